@@ -0,0 +1,78 @@
+package counter
+
+import (
+	"sync"
+	"time"
+)
+
+// Aggregator combines the snapshots that many nodes of a distributed system report periodically,
+// e.g. to a coordinator, into a fleet-wide count and rate.
+type Aggregator struct {
+	mutex      sync.Mutex
+	clock      Clock
+	staleAfter time.Duration
+	nodes      map[string]aggregatedNode
+}
+
+// aggregatedNode is the latest snapshot of a node, and when it was added.
+type aggregatedNode struct {
+	snapshot Snapshot
+	addedAt  time.Time
+}
+
+// NewAggregator returns a new, empty Aggregator.
+// A node that hasn't reported a snapshot within staleAfter is considered stale; if staleAfter is not positive, nodes never go stale.
+func NewAggregator(staleAfter time.Duration) *Aggregator {
+	return &Aggregator{staleAfter: staleAfter, nodes: make(map[string]aggregatedNode)}
+}
+
+// WithClock makes the aggregator read the current time from clock, instead of the system clock.
+func (a *Aggregator) WithClock(clock Clock) *Aggregator {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.clock = clock
+
+	return a
+}
+
+// Add records s as the latest snapshot of the node with the given ID, replacing its previous snapshot.
+func (a *Aggregator) Add(nodeID string, s Snapshot) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.nodes[nodeID] = aggregatedNode{snapshot: s, addedAt: a.now()}
+}
+
+// Total returns the sum of the counts of the latest snapshots of all nodes, and their combined average rate per second.
+// Stale nodes and nodes whose counter was not running still contribute their last count, since those increments happened,
+// but not their rate, since they are not known to increment anymore.
+func (a *Aggregator) Total() (count uint64, rate float64) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	now := a.now()
+
+	for _, node := range a.nodes {
+		count = addSaturating(count, node.snapshot.Count)
+
+		stale := a.staleAfter > 0 && now.Sub(node.addedAt) > a.staleAfter
+		if stale || !node.snapshot.Running || node.snapshot.Interval <= 0 {
+			continue
+		}
+
+		rate += node.snapshot.AverageRate / node.snapshot.Interval.Seconds()
+	}
+
+	return count, rate
+}
+
+// now returns the current time of the clock set via WithClock, or of the system clock.
+// It must be called with a.mutex held.
+func (a *Aggregator) now() time.Time {
+	if a.clock != nil {
+		return a.clock.Now()
+	}
+
+	return time.Now()
+}
@@ -0,0 +1,40 @@
+package counter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestAggregator(t *testing.T) {
+	clock := newFakeClock()
+	a := NewAggregator(time.Minute).WithClock(clock)
+
+	count, rate := a.Total()
+	testza.AssertEqual(t, uint64(0), count)
+	testza.AssertEqual(t, 0.0, rate)
+
+	a.Add("a", Snapshot{Count: 100, Running: true, Interval: time.Second, AverageRate: 10})
+	a.Add("b", Snapshot{Count: 600, Running: true, Interval: time.Minute, AverageRate: 300})
+	a.Add("c", Snapshot{Count: 50, Running: false, Interval: time.Second, AverageRate: 5})
+
+	count, rate = a.Total()
+	testza.AssertEqual(t, uint64(750), count)
+	testza.AssertEqual(t, 15.0, rate)
+
+	// a newer snapshot replaces the previous one of the node
+	clock.Advance(45 * time.Second)
+	a.Add("a", Snapshot{Count: 500, Running: true, Interval: time.Second, AverageRate: 8})
+
+	count, rate = a.Total()
+	testza.AssertEqual(t, uint64(1150), count)
+	testza.AssertEqual(t, 13.0, rate)
+
+	// b stops reporting
+	clock.Advance(30 * time.Second)
+
+	count, rate = a.Total()
+	testza.AssertEqual(t, uint64(1150), count)
+	testza.AssertEqual(t, 8.0, rate)
+}
@@ -0,0 +1,96 @@
+package counter
+
+import "time"
+
+// rateAlarm implements the hysteresis of WithRateAlarm.
+type rateAlarm struct {
+	high, low float64
+	tripped   bool
+}
+
+// observe updates the alarm with the current rate.
+// It reports whether the alarm tripped or cleared with this observation.
+func (a *rateAlarm) observe(rate float64) (tripped, cleared bool) {
+	switch {
+	case !a.tripped && rate > a.high:
+		a.tripped = true
+		return true, false
+	case a.tripped && rate < a.low:
+		a.tripped = false
+		return false, true
+	}
+
+	return false, false
+}
+
+// WithRateAlarm calls onTrip when the rate of the counter exceeds high, and onClear once it dropped below low again.
+// The rate is the number of increments within the last interval, and is evaluated once per interval while the counter is running.
+// Between low and high, nothing happens; this prevents the alarm from flapping when the rate hovers around a single threshold.
+// The interval is measured by the clock set via WithClock, if it is a TimerClock.
+// The callbacks are called from a background goroutine. If interval is not positive, one second is used.
+func (c *Counter) WithRateAlarm(high, low float64, interval time.Duration, onTrip, onClear func()) *Counter {
+	alarm := &rateAlarm{high: high, low: low}
+	interval = tickInterval(interval)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.workers = append(c.workers, func(stop <-chan struct{}) {
+		last := c.Count()
+
+		c.tick(stop, interval, func() {
+			count := c.Count()
+			if count < last {
+				last = 0
+			}
+
+			tripped, cleared := alarm.observe(float64(count - last))
+			last = count
+
+			if tripped && onTrip != nil {
+				onTrip()
+			}
+
+			if cleared && onClear != nil {
+				onClear()
+			}
+		})
+	})
+
+	return c
+}
+
+// WithLowRateAlarm calls fn with the current rate in `count / interval` when it drops below floor, e.g. to detect a collapse of the throughput.
+// The current rate, as returned by CalculateCurrentRate, is evaluated once per evalPeriod while the counter is running.
+// fn is called once per drop; it is called again only after the rate recovered to at least floor and dropped again.
+// A counter that was not incremented since it started has a rate of 0, so it triggers the alarm at the first evaluation.
+// The evaluation period is measured by the clock set via WithClock, if it is a TimerClock.
+// fn is called from a background goroutine. If evalPeriod is not positive, the rate is evaluated once per second.
+func (c *Counter) WithLowRateAlarm(floor float64, interval, evalPeriod time.Duration, fn func(rate float64)) *Counter {
+	evalPeriod = tickInterval(evalPeriod)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.workers = append(c.workers, func(stop <-chan struct{}) {
+		var below bool
+
+		c.tick(stop, evalPeriod, func() {
+			rate := c.CalculateCurrentRate(interval)
+			if rate >= floor {
+				below = false
+				return
+			}
+
+			if !below {
+				below = true
+
+				if fn != nil {
+					fn(rate)
+				}
+			}
+		})
+	})
+
+	return c
+}
@@ -0,0 +1,151 @@
+package counter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestRateAlarm_hysteresis(t *testing.T) {
+	alarm := &rateAlarm{high: 100, low: 50}
+
+	var trips, clears int
+	sweep := []float64{10, 60, 99, 101, 150, 99, 60, 51, 120, 49, 10, 60, 99, 49, 101, 30}
+	for _, rate := range sweep {
+		tripped, cleared := alarm.observe(rate)
+		if tripped {
+			trips++
+		}
+
+		if cleared {
+			clears++
+		}
+	}
+
+	testza.AssertEqual(t, 2, trips)
+	testza.AssertEqual(t, 2, clears)
+}
+
+func TestCounter_WithRateAlarm(t *testing.T) {
+	var trips, clears int
+
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithRateAlarm(50, 5, time.Second, func() { trips++ }, func() { clears++ })
+
+	// not evaluated before the counter runs
+	c.IncrementBy(100)
+	clock.Advance(time.Second)
+	testza.AssertEqual(t, 0, trips)
+
+	c.Start()
+	clock.waitTimers(1)
+
+	c.IncrementBy(100)
+	clock.Advance(time.Second)
+	testza.AssertEqual(t, 1, trips)
+
+	// between the thresholds, nothing happens
+	c.IncrementBy(10)
+	clock.Advance(time.Second)
+	testza.AssertEqual(t, 1, trips)
+	testza.AssertEqual(t, 0, clears)
+
+	clock.Advance(time.Second)
+	testza.AssertEqual(t, 1, trips)
+	testza.AssertEqual(t, 1, clears)
+
+	c.Stop()
+
+	// not evaluated after the counter stopped
+	c.IncrementBy(100)
+	clock.Advance(time.Second)
+	testza.AssertEqual(t, 1, trips)
+}
+
+func TestCounter_WithRateAlarm_InvalidInterval(t *testing.T) {
+	var trips int
+
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithRateAlarm(50, 5, -time.Second, func() { trips++ }, nil).Start()
+	clock.waitTimers(1)
+
+	// the rate is evaluated once per second instead
+	c.IncrementBy(100)
+	clock.Advance(999 * time.Millisecond)
+	testza.AssertEqual(t, 0, trips)
+
+	clock.Advance(time.Millisecond)
+	testza.AssertEqual(t, 1, trips)
+
+	c.Stop()
+}
+
+func TestCounter_WithLowRateAlarm(t *testing.T) {
+	var rates []float64
+
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithLowRateAlarm(20, time.Second, 100*time.Millisecond, func(rate float64) { rates = append(rates, rate) })
+
+	// not evaluated before the counter runs
+	clock.Advance(time.Second)
+	testza.AssertLen(t, rates, 0)
+
+	c.Start()
+	clock.waitTimers(1)
+
+	// a fast pace of 100/s, far above the floor
+	for i := 0; i < 50; i++ {
+		clock.Advance(10 * time.Millisecond)
+		c.Increment()
+	}
+
+	testza.AssertLen(t, rates, 0)
+
+	// the pace collapses, which is reported once
+	clock.Advance(time.Second)
+	testza.AssertLen(t, rates, 1)
+	testza.AssertLess(t, rates[0], 20.0)
+
+	// and again after it recovered
+	for i := 0; i < 50; i++ {
+		clock.Advance(10 * time.Millisecond)
+		c.Increment()
+	}
+
+	clock.Advance(time.Second)
+	testza.AssertLen(t, rates, 2)
+
+	c.Stop()
+
+	// not evaluated after the counter stopped
+	clock.Advance(time.Second)
+	testza.AssertLen(t, rates, 2)
+}
+
+func TestCounter_WithLowRateAlarm_InvalidEvalPeriod(t *testing.T) {
+	var rates []float64
+
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithLowRateAlarm(20, time.Second, 0, func(rate float64) { rates = append(rates, rate) }).Start()
+	clock.waitTimers(1)
+
+	// the rate is evaluated once per second instead
+	clock.Advance(999 * time.Millisecond)
+	testza.AssertLen(t, rates, 0)
+
+	clock.Advance(time.Millisecond)
+	testza.AssertLen(t, rates, 1)
+
+	c.Stop()
+}
+
+func TestCounter_WithLowRateAlarm_NilFunc(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithLowRateAlarm(20, time.Second, time.Second, nil).Start()
+	clock.waitTimers(1)
+
+	testza.AssertNotPanics(t, func() { clock.Advance(time.Second) })
+
+	c.Stop()
+}
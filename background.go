@@ -0,0 +1,109 @@
+package counter
+
+import (
+	"sync"
+	"time"
+)
+
+// worker is a background task that is bound to the lifecycle of a Counter.
+// It is started when the counter starts and must return as soon as stop is closed.
+type worker func(stop <-chan struct{})
+
+// workerRun is a single generation of running workers, started by one call to Start.
+type workerRun struct {
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// startWorkers launches all registered workers.
+// It must be called with c.mutex held.
+func (c *Counter) startWorkers() {
+	if len(c.workers) == 0 {
+		return
+	}
+
+	run := &workerRun{stop: make(chan struct{})}
+	for _, w := range c.workers {
+		run.wg.Add(1)
+
+		go func(w worker) {
+			defer run.wg.Done()
+			w(run.stop)
+		}(w)
+	}
+
+	c.run = run
+}
+
+// stopWorkers signals all running workers to stop, and stops the callbacks of WithDebouncedCallback and WithThrottledCallback.
+// Their pending calls are made right away if deliver is true, and discarded otherwise.
+// It must be called with c.mutex held. The returned function blocks until all workers and callbacks returned,
+// and must be called after c.mutex is released, as they may access the counter while shutting down.
+func (c *Counter) stopWorkers(deliver bool) (wait func()) {
+	callbacks := c.stopCallbacks(deliver)
+
+	run := c.run
+	if run == nil {
+		return callbacks
+	}
+
+	c.run = nil
+	close(run.stop)
+
+	return func() {
+		callbacks()
+		run.wg.Wait()
+	}
+}
+
+// tick calls fn once per interval until stop is closed, measured by the clock of the counter:
+// like the callbacks of WithDebouncedCallback and WithThrottledCallback, it follows a TimerClock set via WithClock.
+// fn is called from the timer of the clock, one call at a time. tick returns after a running call returned.
+// It must be called from a worker, without holding c.mutex.
+func (c *Counter) tick(stop <-chan struct{}, interval time.Duration, fn func()) {
+	var (
+		mutex   sync.Mutex
+		stopped bool
+		timer   Timer
+		call    func()
+	)
+
+	schedule := func() {
+		c.mutex.Lock()
+		timer = c.afterFunc(interval, call)
+		c.mutex.Unlock()
+	}
+
+	call = func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if stopped {
+			return
+		}
+
+		fn()
+		schedule()
+	}
+
+	mutex.Lock()
+	schedule()
+	mutex.Unlock()
+
+	<-stop
+
+	mutex.Lock()
+	stopped = true
+	timer.Stop()
+	mutex.Unlock()
+}
+
+// tickInterval returns d, or one second if d is not positive, which would make tick call its function without pause.
+// Options that tick in a worker must validate their interval with it when they are set.
+func tickInterval(d time.Duration) time.Duration {
+	if d <= 0 {
+		return time.Second
+	}
+
+	return d
+}
@@ -0,0 +1,118 @@
+package counter
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+// waitForGoroutines waits until the number of running goroutines drops to n, or a timeout is reached.
+func waitForGoroutines(n int) int {
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > n && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	return runtime.NumGoroutine()
+}
+
+func TestCounter_backgroundTeardown(t *testing.T) {
+	blockingWorker := func(stop <-chan struct{}) { <-stop }
+
+	t.Run("Stop", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+
+		c := NewCounter()
+		c.workers = append(c.workers, blockingWorker, blockingWorker, blockingWorker)
+		c.Start()
+		testza.AssertGreater(t, runtime.NumGoroutine(), before)
+
+		c.Stop()
+		testza.AssertLessOrEqual(t, waitForGoroutines(before), before)
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+
+		c := NewCounter()
+		c.workers = append(c.workers, blockingWorker, blockingWorker)
+		c.Start()
+		c.Reset()
+		testza.AssertLessOrEqual(t, waitForGoroutines(before), before)
+	})
+
+	t.Run("Restart", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+
+		c := NewCounter()
+		c.workers = append(c.workers, blockingWorker)
+		for i := 0; i < 10; i++ {
+			c.Start()
+			c.Stop()
+		}
+		testza.AssertLessOrEqual(t, waitForGoroutines(before), before)
+	})
+}
+
+// discardStatsD is a StatsDClient that drops all metrics.
+type discardStatsD struct{}
+
+func (discardStatsD) Count(string, int64, []string, float64) error { return nil }
+
+// TestCounter_backgroundTeardown_allFeatures enables every feature that runs in the background,
+// and asserts that neither their goroutines nor their timers outlive the teardown of the counter.
+func TestCounter_backgroundTeardown_allFeatures(t *testing.T) {
+	teardowns := map[string]func(c *Counter){
+		"Stop":        (*Counter).Stop,
+		"StopAndWait": (*Counter).StopAndWait,
+		"Reset":       (*Counter).Reset,
+	}
+
+	for name, teardown := range teardowns {
+		t.Run(name, func(t *testing.T) {
+			var debounced, throttled recordedCounts
+
+			before := runtime.NumGoroutine()
+
+			c := NewCounter().
+				WithStatsD(discardStatsD{}, "events", time.Millisecond).
+				WithRateAlarm(1e9, 0, time.Millisecond, func() {}, func() {}).
+				WithLowRateAlarm(1e9, time.Second, time.Millisecond, func(float64) {}).
+				WithDebouncedCallback(5*time.Millisecond, debounced.record).
+				WithThrottledCallback(5*time.Millisecond, throttled.record).
+				Start()
+
+			testza.AssertGreaterOrEqual(t, runtime.NumGoroutine(), before+3)
+
+			// leave a call of both callbacks pending
+			c.Increment()
+			time.Sleep(10 * time.Millisecond)
+			c.Increment()
+
+			teardown(c)
+			testza.AssertLessOrEqual(t, waitForGoroutines(before), before)
+
+			if name == "StopAndWait" {
+				counts := debounced.get()
+				testza.AssertEqual(t, uint64(2), counts[len(counts)-1])
+			}
+
+			debouncedCalls, throttledCalls := len(debounced.get()), len(throttled.get())
+
+			// the pending calls were either made or discarded, and no timer fires anymore
+			time.Sleep(20 * time.Millisecond)
+			testza.AssertLen(t, debounced.get(), debouncedCalls)
+			testza.AssertLen(t, throttled.get(), throttledCalls)
+
+			for _, l := range c.callbacks {
+				l.mutex.Lock()
+				testza.AssertFalse(t, l.pending)
+				l.mutex.Unlock()
+			}
+
+			testza.AssertLessOrEqual(t, runtime.NumGoroutine(), before)
+		})
+	}
+}
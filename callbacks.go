@@ -0,0 +1,133 @@
+package counter
+
+import (
+	"sync"
+	"time"
+)
+
+// limitedCallback calls a function with the current count of a counter, at most once per interval.
+type limitedCallback struct {
+	counter  *Counter
+	interval time.Duration
+	fn       func(count uint64)
+	// leading makes the first trigger after an idle interval call immediately, instead of after an interval.
+	leading bool
+
+	mutex   sync.Mutex
+	pending bool
+	// lastCall is the time on the clock of the counter that the latest call was scheduled for.
+	lastCall time.Time
+	timer    Timer
+	// done is closed when the latest scheduled call returned.
+	done chan struct{}
+}
+
+// trigger schedules a call after an increment at now, unless one is already pending.
+// It must be called with the lock of the counter held.
+func (l *limitedCallback) trigger(now time.Time) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.pending {
+		return
+	}
+
+	l.pending = true
+
+	delay := l.interval
+	if l.leading {
+		delay = max(l.interval-now.Sub(l.lastCall), 0)
+	}
+
+	done := make(chan struct{})
+	l.done = done
+	l.lastCall = now.Add(delay)
+	l.timer = l.counter.afterFunc(delay, func() { l.call(done) })
+}
+
+// call calls the function with the latest count, and closes done afterwards.
+func (l *limitedCallback) call(done chan struct{}) {
+	defer close(done)
+
+	l.mutex.Lock()
+	l.pending = false
+	l.mutex.Unlock()
+
+	l.fn(l.counter.Count())
+}
+
+// stop stops a pending call. If deliver is true, the returned function makes the call right away instead,
+// so it receives the latest count. In either case, the returned function blocks until a call that already started returned.
+// The returned function must be called without holding the lock of the counter.
+func (l *limitedCallback) stop(deliver bool) (wait func()) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	done := l.done
+	if done == nil {
+		return func() {}
+	}
+
+	if l.pending && l.timer.Stop() {
+		if deliver {
+			return func() { l.call(done) }
+		}
+
+		l.pending = false
+		close(done)
+
+		return func() {}
+	}
+
+	return func() { <-done }
+}
+
+// stopCallbacks stops the pending calls of all callbacks, see limitedCallback.stop.
+// It must be called with c.mutex held. The returned function must be called after c.mutex is released.
+func (c *Counter) stopCallbacks(deliver bool) (wait func()) {
+	waits := make([]func(), 0, len(c.callbacks))
+	for _, l := range c.callbacks {
+		waits = append(waits, l.stop(deliver))
+	}
+
+	return func() {
+		for _, wait := range waits {
+			wait()
+		}
+	}
+}
+
+// WithDebouncedCallback calls fn with the latest count after increments, but at most once per interval.
+// Increments within an interval are coalesced into a single call, so fn is called far less often than the counter is incremented.
+// After the last increment, fn is always called once more with the final count, unless the counter is stopped via Stop or reset before;
+// StopAndWait makes that call right away.
+// fn is called from a background goroutine.
+func (c *Counter) WithDebouncedCallback(interval time.Duration, fn func(count uint64)) *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.callbacks = append(c.callbacks, &limitedCallback{counter: c, interval: interval, fn: fn})
+
+	return c
+}
+
+// WithThrottledCallback calls fn with the latest count after increments, at most once per maxInterval.
+// Unlike WithDebouncedCallback, the first increment after an idle period calls fn right away,
+// and continuous increments call fn regularly, once per maxInterval.
+// After the last increment, fn is always called once more with the final count, unless the counter is stopped via Stop or reset before;
+// StopAndWait makes that call right away.
+// fn is called from a background goroutine.
+func (c *Counter) WithThrottledCallback(maxInterval time.Duration, fn func(count uint64)) *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.callbacks = append(c.callbacks, &limitedCallback{counter: c, interval: maxInterval, fn: fn, leading: true})
+
+	return c
+}
+
+// StopAndWait stops the counter like Stop, and then blocks until all pending callbacks of WithDebouncedCallback and WithThrottledCallback were called.
+// Pending callbacks are called right away, instead of after their interval, so they receive the final count.
+func (c *Counter) StopAndWait() {
+	c.stop(true)
+}
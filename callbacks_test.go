@@ -0,0 +1,164 @@
+package counter
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+// recordedCounts records the counts a callback was called with.
+type recordedCounts struct {
+	mutex  sync.Mutex
+	counts []uint64
+}
+
+func (r *recordedCounts) record(count uint64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.counts = append(r.counts, count)
+}
+
+func (r *recordedCounts) get() []uint64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]uint64(nil), r.counts...)
+}
+
+func TestCounter_WithDebouncedCallback(t *testing.T) {
+	var calls recordedCounts
+
+	c := NewCounter().WithDebouncedCallback(10*time.Millisecond, calls.record).Start()
+
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 1000; j++ {
+			c.Increment()
+		}
+		time.Sleep(15 * time.Millisecond)
+	}
+
+	for i := 0; i < 1000; i++ {
+		c.Increment()
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		counts := calls.get()
+		if len(counts) > 0 && counts[len(counts)-1] == 4000 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	counts := calls.get()
+	testza.AssertGreater(t, len(counts), 0)
+	testza.AssertLess(t, len(counts), 10)
+	testza.AssertEqual(t, uint64(4000), counts[len(counts)-1])
+}
+
+func TestCounter_WithThrottledCallback(t *testing.T) {
+	var calls recordedCounts
+
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithThrottledCallback(10*time.Millisecond, calls.record).Start()
+
+	c.Increment()
+	clock.Advance(0)
+	testza.AssertEqual(t, []uint64{1}, calls.get(), "the leading call should happen right away")
+
+	// increment continuously for 100ms, once per millisecond
+	for i := 0; i < 100; i++ {
+		clock.Advance(time.Millisecond)
+		c.Increment()
+	}
+
+	clock.Advance(10 * time.Millisecond)
+
+	// a leading call, one call per 10ms while incrementing, and a trailing call with the final count
+	counts := calls.get()
+	testza.AssertEqual(t, []uint64{1, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100, 101}, counts)
+	testza.AssertEqual(t, c.Count(), counts[len(counts)-1])
+
+	// after an idle interval, the next call is a leading one again
+	clock.Advance(time.Hour)
+	c.Increment()
+	clock.Advance(0)
+	testza.AssertEqual(t, uint64(102), calls.get()[len(calls.get())-1])
+}
+
+func TestCounter_StopAndWait(t *testing.T) {
+	var debounced, throttled recordedCounts
+
+	c := NewCounter().WithAdvancedStats().
+		WithDebouncedCallback(time.Hour, debounced.record).
+		WithThrottledCallback(time.Hour, throttled.record).
+		Start()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 1000; j++ {
+				c.Increment()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	start := time.Now()
+	c.StopAndWait()
+	testza.AssertLess(t, time.Since(start).Seconds(), 1.0)
+
+	testza.AssertEqual(t, StateStopped, c.State())
+	testza.AssertLen(t, c.triggers, 4000)
+	testza.AssertEqual(t, []uint64{4000}, debounced.get())
+	testza.AssertEqual(t, uint64(4000), throttled.get()[len(throttled.get())-1])
+
+	// nothing is pending anymore
+	c.StopAndWait()
+	testza.AssertEqual(t, []uint64{4000}, debounced.get())
+}
+
+func TestCounter_limitedCallback_Stop(t *testing.T) {
+	options := map[string]func(c *Counter, fn func(count uint64)) *Counter{
+		"Debounced": func(c *Counter, fn func(count uint64)) *Counter {
+			return c.WithDebouncedCallback(10*time.Millisecond, fn)
+		},
+		"Throttled": func(c *Counter, fn func(count uint64)) *Counter {
+			return c.WithThrottledCallback(10*time.Millisecond, fn)
+		},
+	}
+	stops := map[string]func(c *Counter){
+		"Stop":  (*Counter).Stop,
+		"Reset": (*Counter).Reset,
+	}
+
+	for option, with := range options {
+		for name, stop := range stops {
+			t.Run(option+"/"+name, func(t *testing.T) {
+				var calls recordedCounts
+
+				clock := newFakeClock()
+				c := with(NewCounter().WithClock(clock), calls.record).Start()
+
+				// the throttled callback makes a leading call right away, the next one is pending
+				c.Increment()
+				clock.Advance(0)
+				c.Increment()
+
+				before := calls.get()
+				stop(c)
+				clock.Advance(time.Hour)
+
+				testza.AssertEqual(t, before, calls.get())
+			})
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package counter
+
+// CountReader is implemented by everything that has a current count, like Counter and Computed.
+type CountReader interface {
+	Count() uint64
+}
+
+var (
+	_ CountReader = (*Counter)(nil)
+	_ CountReader = (*Computed)(nil)
+)
+
+// Computed is a read-only counter, whose count is derived from other values, e.g. the sum of other counters.
+type Computed struct {
+	fn func() uint64
+}
+
+// NewComputed returns a new Computed, whose count is computed by calling fn.
+// fn is called on every call to Count, so the count always reflects the current state of its sources.
+func NewComputed(fn func() uint64) *Computed {
+	return &Computed{fn: fn}
+}
+
+// Count returns the current count, as computed by the function passed to NewComputed.
+func (c *Computed) Count() uint64 {
+	return c.fn()
+}
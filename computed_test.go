@@ -0,0 +1,30 @@
+package counter_test
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+
+	"atomicgo.dev/counter"
+)
+
+func TestComputed(t *testing.T) {
+	a := counter.NewCounter().Start()
+	b := counter.NewCounter().Start()
+
+	var sum counter.CountReader = counter.NewComputed(func() uint64 {
+		return a.Count() + b.Count()
+	})
+	testza.AssertEqual(t, uint64(0), sum.Count())
+
+	for i := 0; i < 10; i++ {
+		a.Increment()
+		testza.AssertEqual(t, uint64(i+1), sum.Count())
+	}
+
+	b.IncrementBy(5)
+	testza.AssertEqual(t, uint64(15), sum.Count())
+
+	a.Reset()
+	testza.AssertEqual(t, uint64(5), sum.Count())
+}
@@ -5,8 +5,18 @@ It collects statstics, like current rate, min / max rate, etc.
 package counter
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"math"
+	"math/big"
+	"slices"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unsafe"
 )
 
 // Counter is a fast, thread-safe counter.
@@ -15,11 +25,145 @@ import (
 type Counter struct {
 	mutex       sync.Mutex
 	count       uint64
-	started     bool
+	weighted    atomic.Uint64 // float64 bits, see AddWeighted
+	state       State
 	startedAt   time.Time
 	stoppedAt   time.Time
 	triggers    []time.Time
 	enableStats bool
+	strictStats bool
+	sampling    uint64
+	sampleTick  uint64
+	warmup      uint64
+	warmedUp    uint64
+	rateBase    uint64
+	maxSamples  int
+	ringHead    int
+
+	totalIncremented uint64
+	totalDecremented uint64
+
+	lightStats   bool
+	lightSamples int
+	lightLast    time.Time
+	lightMinDiff time.Duration
+	lightMaxDiff time.Duration
+	lightHasDiff bool
+
+	// extremesFrom is the time of the ResetExtremes call
+	extremesFrom time.Time
+
+	diffStorage bool
+	gaps        []time.Duration
+	gapsFrom    time.Time
+	gapsTo      time.Time
+
+	workers []worker
+	run     *workerRun
+
+	logger    *slog.Logger
+	clock     Clock
+	wallClock bool
+
+	zeroWaiters []chan struct{}
+	onReset     []func()
+	emitTo      []chan<- uint64
+	callbacks   []*limitedCallback
+	statsD      []*statsDEmitter
+	rolling     *rollingBuckets
+
+	onFirstIncrement func()
+
+	monotonicCheck bool
+	regressed      bool
+	onRegression   func(previous, current uint64)
+
+	defaultInterval time.Duration
+	lastIncrementAt time.Time
+	gapAverage      float64
+	pendingRate     uint64
+	rateCarryOver   bool
+
+	seedMinDiff time.Duration
+	seedMaxDiff time.Duration
+
+	markAt          time.Time
+	markIncremented uint64
+
+	baselineRate     float64
+	baselineInterval time.Duration
+
+	tags map[string]uint64
+
+	significant *significantChange
+
+	observers []Observer
+
+	roundRates    bool
+	ratePrecision int
+
+	median *runningMedian
+
+	freshWindowOnStart bool
+
+	preIncrement func(current uint64) bool
+
+	metadata map[string]string
+
+	pausedAt    time.Time
+	pausedTotal time.Duration
+
+	autoStart bool
+
+	movingAverage *movingAverage
+}
+
+// State is the lifecycle state of a Counter.
+type State int
+
+const (
+	// StateNeverStarted is the state of a counter that was not started yet, or was reset.
+	StateNeverStarted State = iota
+	// StateRunning is the state of a started counter.
+	StateRunning
+	// StateStopped is the state of a counter that was started and then stopped.
+	StateStopped
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateNeverStarted:
+		return "never started"
+	case StateRunning:
+		return "running"
+	case StateStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
+// Clock provides the current time to a Counter.
+// It can be replaced via WithClock, e.g. to control the time in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// TimerClock is a Clock that can also schedule calls, like time.AfterFunc.
+// If the clock set via WithClock implements it, the timers of the counter, like the ones of WithThrottledCallback,
+// follow the clock too, e.g. so that a fake clock controls them in tests. Otherwise, they follow the system clock.
+type TimerClock interface {
+	Clock
+	// AfterFunc calls f once d has passed on the clock, unless the returned Timer is stopped before.
+	// f must not be called before AfterFunc returned; a fake clock can call it when it is advanced, for example.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a call scheduled by TimerClock.AfterFunc. It is implemented by *time.Timer.
+type Timer interface {
+	// Stop prevents the call. It returns false if the call was already made or stopped.
+	Stop() bool
 }
 
 // NewCounter returns a new Counter.
@@ -32,140 +176,1554 @@ func NewCounter() *Counter {
 
 // WithAdvancedStats enables the calculation of advanced statistics like CalculateMinimumRate and CalculateMaximumRate.
 // CalculateAverageRate and CalculateCurrentRate are always enabled.
+// It enables them on the counter itself and returns it, so it can be chained with the other options.
+// Earlier versions returned a new counter instead, dropping the configuration and the count of the receiver.
 func (c *Counter) WithAdvancedStats() *Counter {
-	cNew := NewCounter()
-	cNew.enableStats = true
-	return cNew
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.enableStats = true
+
+	return c
 }
 
-// Start starts the counter.
-// It returns the counter itself, so you can chain it.
-func (c *Counter) Start() *Counter {
+// WithWarmup excludes the first n increments from the rates and statistics of the counter, e.g. when they include a slow connection setup.
+// The warm-up increments are still counted. After the last one, the measured time span restarts, so the rates only reflect the pace after the warm-up.
+// IncrementBy counts as its number of increments; if it crosses the end of the warm-up, only the part after it is included in the rates.
+// The warm-up starts again after a reset.
+func (c *Counter) WithWarmup(n int) *Counter {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	if c.started {
-		return c
-	}
+	c.warmup = uint64(max(n, 0))
+
+	return c
+}
 
-	c.started = true
-	c.startedAt = time.Now()
+// WithStrictStats makes CalculateMinimumRate and CalculateMaximumRate panic with ErrStatsDisabled,
+// if advanced stats are not enabled via WithAdvancedStats, instead of silently returning 0.
+// It helps to catch a misconfigured counter early.
+func (c *Counter) WithStrictStats() *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.strictStats = true
 
 	return c
 }
 
-// Stop stops the counter.
-func (c *Counter) Stop() {
+// WithSampling makes the advanced stats record only every nth increment, to reduce their cost at very high rates.
+// The rates derived from the recorded increments are scaled accordingly, trading precision for throughput.
+// A value of 1 or lower records every increment.
+func (c *Counter) WithSampling(n int) *Counter {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	if !c.started {
-		return
+	c.sampling = 0
+	if n > 1 {
+		c.sampling = uint64(n)
 	}
 
-	c.stoppedAt = time.Now()
-	c.started = false
+	return c
 }
 
-// Increment increments the counter by 1.
-func (c *Counter) Increment() {
+// WithDefaultInterval sets the interval of the rates returned by AverageRate and CurrentRate.
+// The default is one second.
+func (c *Counter) WithDefaultInterval(d time.Duration) *Counter {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	c.count++
-	if c.enableStats {
-		now := time.Now()
-		c.triggers = append(c.triggers, now)
-	}
+	c.defaultInterval = d
+
+	return c
 }
 
-// Count returns the current count.
-func (c *Counter) Count() uint64 {
+// WithFreshWindowOnStart makes every Start of a counter that is not running, including after Stop, behave like StartFresh:
+// the count of previous runs is kept, but excluded from the rates, and the statistics are cleared.
+// This gives per-run rates, while Count still returns the cumulative total.
+func (c *Counter) WithFreshWindowOnStart() *Counter {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	return c.count
+	c.freshWindowOnStart = true
+
+	return c
 }
 
-// Reset stops and resets the counter.
-func (c *Counter) Reset() {
+// WithPreIncrement sets a hook that is called with the current count before every increment, e.g. to enforce a quota.
+// If fn returns false, the increment is cancelled and the count is unchanged; TryIncrement and IncrementIfRunning report it.
+// fn is called while holding the lock of the counter, so it must not call methods of the counter. Pass nil to remove the hook.
+func (c *Counter) WithPreIncrement(fn func(current uint64) bool) *Counter {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	c.count = 0
-	c.startedAt = time.Time{}
-	c.stoppedAt = time.Now()
-	c.started = false
+	c.preIncrement = fn
+
+	return c
 }
 
-// CalculateAverageRate calculates the average rate of the counter.
-// It returns the rate in `count / interval`.
-func (c *Counter) CalculateAverageRate(interval time.Duration) float64 {
+// WithMetadata attaches labels to the counter, like the environment, service or region, e.g. to route its snapshots to the right dashboard.
+// The counter keeps a copy of metadata, which is meant to be set once while constructing the counter, and is included in its snapshots.
+func (c *Counter) WithMetadata(metadata map[string]string) *Counter {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	if c.count == 0 {
-		return 0
+	c.metadata = maps.Clone(metadata)
+
+	return c
+}
+
+// Metadata returns a copy of the labels set via WithMetadata, or nil if there are none.
+func (c *Counter) Metadata() map[string]string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return maps.Clone(c.metadata)
+}
+
+// WithAutoStart makes the first increment start the counter, if it was not started yet, so that it can't be forgotten.
+// The measured time span begins with that increment. After Reset, the next increment starts the counter again;
+// a stopped counter is not restarted.
+func (c *Counter) WithAutoStart() *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.autoStart = true
+
+	return c
+}
+
+// WithRateCarryOver makes Reset keep the smoothed estimate of the current rate, as returned by CalculateCurrentRate,
+// while the count and all other statistics are cleared.
+// This keeps a live chart of the current rate continuous across intervals that end with a Reset.
+func (c *Counter) WithRateCarryOver() *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.rateCarryOver = true
+
+	return c
+}
+
+// WithMonotonicCheck makes the counter watch for decreases of its count by Decrement, DecrementBy, Done, Add or Set,
+// which are unexpected for a pure event counter. Decreases are reported by HadRegression.
+// If onRegression is not nil, it is called with the previous and the new count on every decrease.
+// Reset and CopyFrom are not considered decreases.
+func (c *Counter) WithMonotonicCheck(onRegression func(previous, current uint64)) *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.monotonicCheck = true
+	c.onRegression = onRegression
+
+	return c
+}
+
+// HadRegression reports whether the count ever decreased since WithMonotonicCheck was called.
+func (c *Counter) HadRegression() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.regressed
+}
+
+// WithOnReset registers fn to be called after each reset of the counter.
+// fn is called without holding the lock of the counter, so it may access the counter.
+func (c *Counter) WithOnReset(fn func()) *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.onReset = append(c.onReset, fn)
+
+	return c
+}
+
+// FirstIncrement registers fn to be called exactly once, by the increment that moves the count from 0 to 1.
+// Even if many goroutines race for the first increment, fn is called only once; the other increments don't wait for it.
+// fn is called without holding the lock of the counter, so it may access the counter.
+func (c *Counter) FirstIncrement(fn func()) *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.onFirstIncrement = fn
+
+	return c
+}
+
+// EmitTo makes the counter send the new count to ch after each increment, e.g. to drive a live progress feed.
+// Sending never blocks: if ch is full, the value is dropped.
+func (c *Counter) EmitTo(ch chan<- uint64) *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.emitTo = append(c.emitTo, ch)
+
+	return c
+}
+
+// WithClock makes the counter read the current time from clock, instead of the system clock.
+func (c *Counter) WithClock(clock Clock) *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.clock = clock
+
+	return c
+}
+
+// WithWallClock makes the counter measure all times and durations with the wall clock, e.g. for replaying or analyzing historical data.
+//
+// By default, the counter uses the monotonic clock readings that time.Now includes, so the measured durations and rates
+// are immune to adjustments of the system clock, like NTP corrections or a changed time zone offset.
+// With the wall clock, recorded increments carry plain wall clock timestamps, which compare correctly with timestamps
+// from other sources or processes, but a jump of the system clock distorts the rates and statistics.
+func (c *Counter) WithWallClock() *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.wallClock = true
+
+	return c
+}
+
+// WithLogger logs lifecycle events of the counter, like starting, stopping and resetting, to l at debug level.
+// Each entry contains the current count. To identify the counter, add attributes to the logger, e.g. l.With("counter", "requests").
+func (c *Counter) WithLogger(l *slog.Logger) *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.logger = l
+
+	return c
+}
+
+// Start starts the counter.
+// Starting a running counter does nothing, use StartFresh to restart its measured time span. Starting a stopped counter starts a new measured time span.
+// The count of previous runs is kept, and by default still included in the rates; use WithFreshWindowOnStart for per-run rates.
+// It returns the counter itself, so you can chain it.
+func (c *Counter) Start() *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.state != StateRunning {
+		c.start()
 	}
 
-	untilTime := c.stoppedAt
-	if untilTime.Before(c.startedAt) {
-		untilTime = time.Now()
+	return c
+}
+
+// start starts the counter, which must not be running.
+// It must be called with c.mutex held.
+func (c *Counter) start() {
+	if c.freshWindowOnStart {
+		c.rateBase = c.count
+		c.clearStats()
 	}
 
-	return float64(c.count) / float64(untilTime.Sub(c.startedAt)) * float64(interval)
+	c.state = StateRunning
+	c.startedAt = c.now()
+	c.clearPauses()
+	c.startWorkers()
+	c.log("counter started")
 }
 
-// CalculateMaximumRate calculates the maximum rate of the counter.
-// It returns the rate in `count / interval`.
-// It returns 0 if the counter has not been started yet.
-// Needs to be enabled via WithAdvancedStats.
-func (c *Counter) CalculateMaximumRate(interval time.Duration) float64 {
+// StartFresh starts the counter like Start, and also restarts the measured time span if the counter is already running.
+// The statistics are cleared, but the count is kept, so the rates only reflect the increments from now on.
+// It returns the counter itself, so you can chain it.
+func (c *Counter) StartFresh() *Counter {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	if !c.enableStats {
-		return 0
+	if c.state != StateRunning {
+		c.state = StateRunning
+		c.startWorkers()
+		c.log("counter started")
 	}
 
-	if len(c.triggers) == 0 {
-		return 0
-	}
+	c.startedAt = c.now()
+	c.clearPauses()
+	c.rateBase = c.count
+	c.clearStats()
 
-	min := time.Duration(-1)
-	for i := 1; i < len(c.triggers); i++ {
-		diff := c.triggers[i].Sub(c.triggers[i-1])
-		if diff < min || min == -1 {
-			min = diff
+	return c
+}
+
+// Stop stops the counter.
+// Stopping a counter that is not running does nothing.
+// It blocks until all background tasks of the counter have shut down.
+// Pending calls of WithDebouncedCallback and WithThrottledCallback are discarded; use StopAndWait to make them.
+func (c *Counter) Stop() {
+	c.stop(false)
+}
+
+// stop stops the counter. Pending calls of the limited callbacks are made right away if deliver is true, and discarded otherwise.
+// If the counter is not running, only the pending calls are made, if deliver is true.
+func (c *Counter) stop(deliver bool) {
+	c.mutex.Lock()
+
+	if c.state != StateRunning {
+		wait := func() {}
+		if deliver {
+			wait = c.stopCallbacks(true)
 		}
+
+		c.mutex.Unlock()
+		wait()
+
+		return
+	}
+
+	c.stoppedAt = c.now()
+	c.state = StateStopped
+
+	if !c.pausedAt.IsZero() {
+		c.pausedTotal += max(c.stoppedAt.Sub(c.pausedAt), 0)
+		c.pausedAt = time.Time{}
 	}
 
-	return float64(interval) / float64(min)
+	c.log("counter stopped")
+	wait := c.stopWorkers(deliver)
+	observers := c.observers
+	c.mutex.Unlock()
+
+	wait()
+
+	for _, o := range observers {
+		o.OnStop()
+	}
 }
 
-// CalculateMinimumRate calculates the minimum rate of the counter.
-// It returns the rate in `count / interval`.
-// It returns 0 if the counter has not been started yet.
-// Needs to be enabled via WithAdvancedStats.
-func (c *Counter) CalculateMinimumRate(interval time.Duration) float64 {
+// Increment increments the counter by 1.
+func (c *Counter) Increment() {
+	c.IncrementBy(1)
+}
+
+// TryIncrement increments the counter by 1, like Increment, and reports whether it was incremented,
+// i.e. not vetoed by the hook set via WithPreIncrement.
+func (c *Counter) TryIncrement() bool {
 	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	after, ok := c.tryIncrement(1, time.Time{})
+	c.mutex.Unlock()
 
-	if !c.enableStats {
-		return 0
+	after()
+
+	return ok
+}
+
+// IncrementBy increments the counter by n.
+// The count saturates at the maximum uint64 value instead of overflowing.
+// With advanced stats enabled, the call is recorded as a single increment.
+func (c *Counter) IncrementBy(n uint64) {
+	if n == 0 {
+		return
 	}
 
-	if len(c.triggers) == 0 {
-		return 0
+	c.mutex.Lock()
+	after, _ := c.tryIncrement(n, time.Time{})
+	c.mutex.Unlock()
+
+	after()
+}
+
+// IncrementByErr increments the counter by n like IncrementBy, but returns ErrOverflow and leaves the count unchanged
+// if the count would exceed the maximum uint64 value, instead of saturating it.
+// A vetoed increment via WithPreIncrement is not an error.
+func (c *Counter) IncrementByErr(n uint64) error {
+	if n == 0 {
+		return nil
 	}
 
-	max := time.Duration(0)
-	for i := 1; i < len(c.triggers); i++ {
-		diff := c.triggers[i].Sub(c.triggers[i-1])
-		if diff > max {
-			max = diff
-		}
+	c.mutex.Lock()
+
+	if c.count > math.MaxUint64-n {
+		c.mutex.Unlock()
+		return fmt.Errorf("incrementing %d by %d: %w", c.count, n, ErrOverflow)
+	}
+
+	after, _ := c.tryIncrement(n, time.Time{})
+	c.mutex.Unlock()
+
+	after()
+
+	return nil
+}
+
+// IncrementIfRunning increments the counter by 1, only if it is running.
+// It reports whether the counter was incremented.
+func (c *Counter) IncrementIfRunning() bool {
+	c.mutex.Lock()
+
+	if c.state != StateRunning {
+		c.mutex.Unlock()
+		return false
+	}
+
+	after, ok := c.tryIncrement(1, time.Time{})
+	c.mutex.Unlock()
+
+	after()
+
+	return ok
+}
+
+// IncrementAt increments the counter by 1, and records the increment at t instead of now.
+// It can be used to replay historical events, so that the advanced stats reflect their original timing.
+// Timestamps don't need to arrive in order.
+func (c *Counter) IncrementAt(t time.Time) {
+	c.mutex.Lock()
+	after, _ := c.tryIncrement(1, t)
+	c.mutex.Unlock()
+
+	after()
+}
+
+// Decrement decrements the counter by 1.
+// The count saturates at 0 instead of wrapping around.
+func (c *Counter) Decrement() {
+	c.DecrementBy(1)
+}
+
+// DecrementBy decrements the counter by n.
+// The count saturates at 0 instead of wrapping around.
+// Decrements are not recorded by the advanced stats.
+func (c *Counter) DecrementBy(n uint64) {
+	c.mutex.Lock()
+
+	previous := c.count
+	if n > c.count {
+		c.count = 0
+	} else {
+		c.count -= n
+	}
+
+	c.totalDecremented = addSaturating(c.totalDecremented, previous-c.count)
+	c.notifyZero()
+	after := c.dropped(previous)
+	c.mutex.Unlock()
+
+	after()
+}
+
+// Set sets the count to n.
+// It is not recorded by the advanced stats.
+func (c *Counter) Set(n uint64) {
+	c.mutex.Lock()
+
+	previous := c.count
+	c.count = n
+	c.notifyZero()
+	after := c.dropped(previous)
+	c.mutex.Unlock()
+
+	after()
+}
+
+// State returns the lifecycle state of the counter.
+func (c *Counter) State() State {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.state
+}
+
+// Count returns the current count.
+func (c *Counter) Count() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.count
+}
+
+// TotalIncremented returns the sum of all increments since the counter was created or reset, independent of any decrements.
+// Only the amount that was actually added counts, so it doesn't grow while the count is saturated.
+// Changes via Set are not included; without them, Count equals TotalIncremented minus TotalDecremented.
+func (c *Counter) TotalIncremented() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.totalIncremented
+}
+
+// TotalDecremented returns the sum of all decrements since the counter was created or reset, independent of any increments.
+// Only the amount that was actually removed counts, so decrements below 0 don't count. Changes via Set are not included.
+func (c *Counter) TotalDecremented() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.totalDecremented
+}
+
+// Reset stops and resets the counter, which is then in StateNeverStarted.
+// It blocks until all background tasks of the counter have shut down.
+// Pending calls of WithDebouncedCallback and WithThrottledCallback are discarded.
+func (c *Counter) Reset() {
+	c.resetStopped(false)
+}
+
+// ResetForReuse is like Reset, but keeps the memory allocated for the advanced stats, to be reused by the next increments.
+// It reduces allocations when counters are reused, e.g. via a sync.Pool. Use Compact to release the memory instead.
+func (c *Counter) ResetForReuse() {
+	c.resetStopped(true)
+}
+
+// resetStopped stops and resets the counter, optionally keeping the memory allocated for the advanced stats.
+func (c *Counter) resetStopped(keepMemory bool) {
+	c.mutex.Lock()
+	wait := c.stopWorkers(false)
+	c.mutex.Unlock()
+
+	wait()
+
+	c.mutex.Lock()
+
+	triggers, gaps := c.triggers[:0], c.gaps[:0]
+
+	c.reset()
+	c.startedAt = time.Time{}
+	c.stoppedAt = time.Time{}
+	c.state = StateNeverStarted
+
+	if keepMemory {
+		c.triggers, c.gaps = triggers, gaps
+	}
+
+	after := c.resetHooks()
+	c.mutex.Unlock()
+
+	after()
+}
+
+// ResetKeepRunning resets the count and statistics of the counter, without stopping it.
+// The measured time span of a running counter restarts now, and increments continue to be counted without interruption.
+// If the counter is not running, it behaves like Reset.
+func (c *Counter) ResetKeepRunning() {
+	c.mutex.Lock()
+
+	if c.state != StateRunning {
+		c.mutex.Unlock()
+		c.Reset()
+
+		return
+	}
+
+	c.reset()
+	c.startedAt = c.now()
+	after := c.resetHooks()
+	c.mutex.Unlock()
+
+	after()
+}
+
+// resetHooks returns a function that calls the callbacks registered via WithOnReset and the observers after a reset.
+// It must be called with c.mutex held; the returned function must be called without it.
+func (c *Counter) resetHooks() (after func()) {
+	onReset, observers := c.onReset, c.observers
+
+	return func() {
+		for _, fn := range onReset {
+			fn()
+		}
+
+		for _, o := range observers {
+			o.OnReset()
+		}
+	}
+}
+
+// ResetAt resets the count and statistics of the counter like ResetKeepRunning, but restarts the measured time span at start
+// instead of now, e.g. at the top of the minute for fixed windows that are aligned to the wall clock.
+// Because each window starts exactly where the previous one should have ended, late rollovers don't accumulate drift.
+// Times like time.Now().Truncate(time.Minute) have no monotonic clock reading, so the time span is measured with the wall clock.
+// If the counter is not running, it is started.
+func (c *Counter) ResetAt(start time.Time) {
+	c.mutex.Lock()
+
+	c.reset()
+	c.startedAt = start
+	c.stoppedAt = time.Time{}
+
+	if c.state != StateRunning {
+		c.state = StateRunning
+		c.startWorkers()
+		c.log("counter started")
+	}
+
+	after := c.resetHooks()
+	c.mutex.Unlock()
+
+	after()
+}
+
+// reset resets the count and statistics of the counter.
+// It must be called with c.mutex held.
+func (c *Counter) reset() {
+	c.count = 0
+	c.totalIncremented = 0
+	c.totalDecremented = 0
+	c.markAt = time.Time{}
+	c.markIncremented = 0
+	c.weighted.Store(0)
+	c.warmedUp = 0
+	c.rateBase = 0
+	c.tags = nil
+	c.clearPauses()
+	c.updateCurrentRate(c.until())
+
+	lastIncrementAt, gapAverage := c.lastIncrementAt, c.gapAverage
+	c.clearStats()
+
+	if c.rateCarryOver {
+		c.lastIncrementAt, c.gapAverage = lastIncrementAt, gapAverage
+	}
+
+	c.notifyZero()
+	c.log("counter reset")
+}
+
+// clearStats clears the statistics of the counter, but keeps the count.
+// It must be called with c.mutex held.
+func (c *Counter) clearStats() {
+	c.triggers = nil
+	c.gaps = nil
+	c.ringHead = 0
+	c.sampleTick = 0
+	c.lightSamples = 0
+	c.lightLast = time.Time{}
+	c.lightMinDiff = 0
+	c.lightMaxDiff = 0
+	c.lightHasDiff = false
+	c.extremesFrom = time.Time{}
+	c.lastIncrementAt = time.Time{}
+	c.gapAverage = 0
+	c.pendingRate = 0
+	c.seedMinDiff = 0
+	c.seedMaxDiff = 0
+
+	if c.rolling != nil {
+		c.rolling.clear()
+	}
+
+	if c.significant != nil {
+		c.significant.last = 0
+	}
+
+	if c.median != nil {
+		*c.median = runningMedian{}
+	}
+
+	if c.movingAverage != nil {
+		c.movingAverage.clear()
+	}
+}
+
+// CopyFrom overwrites the state of the counter with the state of src, e.g. to reuse a pooled counter.
+// It copies the count, the start and stop times, the running state, the metadata, the per-tag counts and the advanced stats, including their configuration.
+// The clock, logger and background tasks of the counter are kept.
+// Both counters are independent of each other afterwards.
+func (c *Counter) CopyFrom(src *Counter) {
+	if c == src {
+		return
+	}
+
+	// lock both counters in a consistent order, so that concurrent copies in both directions can't deadlock
+	first, second := c, src
+	if uintptr(unsafe.Pointer(src)) < uintptr(unsafe.Pointer(c)) {
+		first, second = src, c
+	}
+
+	first.mutex.Lock()
+	second.mutex.Lock()
+
+	wasRunning := c.state == StateRunning
+
+	c.count = src.count
+	c.totalIncremented = src.totalIncremented
+	c.totalDecremented = src.totalDecremented
+	c.markAt = src.markAt
+	c.markIncremented = src.markIncremented
+	c.weighted.Store(src.weighted.Load())
+	c.state = src.state
+	c.startedAt = src.startedAt
+	c.stoppedAt = src.stoppedAt
+	c.pausedAt = src.pausedAt
+	c.pausedTotal = src.pausedTotal
+	c.triggers = nil
+	c.gaps = nil
+	c.diffStorage = src.diffStorage
+	c.setSamples(slices.Clone(src.samples()))
+	c.maxSamples = src.maxSamples
+	c.enableStats = src.enableStats
+	c.strictStats = src.strictStats
+	c.lightStats = src.lightStats
+	c.lightSamples = src.lightSamples
+	c.lightLast = src.lightLast
+	c.lightMinDiff = src.lightMinDiff
+	c.lightMaxDiff = src.lightMaxDiff
+	c.lightHasDiff = src.lightHasDiff
+	c.extremesFrom = src.extremesFrom
+	c.sampling = src.sampling
+	c.sampleTick = src.sampleTick
+	c.warmup = src.warmup
+	c.warmedUp = src.warmedUp
+	c.rateBase = src.rateBase
+	c.lastIncrementAt = src.lastIncrementAt
+	c.gapAverage = src.gapAverage
+	c.pendingRate = src.pendingRate
+	c.seedMinDiff = src.seedMinDiff
+	c.seedMaxDiff = src.seedMaxDiff
+	c.metadata = src.metadata
+	c.tags = maps.Clone(src.tags)
+	c.notifyZero()
+
+	wait := func() {}
+	if running := c.state == StateRunning; running && !wasRunning {
+		c.startWorkers()
+	} else if !running && wasRunning {
+		wait = c.stopWorkers(false)
+	}
+
+	second.mutex.Unlock()
+	first.mutex.Unlock()
+
+	wait()
+}
+
+// CalculateAverageRate calculates the average rate of the counter.
+// It returns the rate in `count / interval`.
+// The rate is a float64, so it is accurate to about 16 significant digits; above 2^53, not every count can be represented exactly.
+// Use CalculateAverageRateExact if that matters.
+// While the counter is running, the time span ends now, so the rate decreases between calls without new increments
+// as the elapsed time grows. Use CalculateAverageRateAt, Stop or a Snapshot to get a stable value for reporting.
+func (c *Counter) CalculateAverageRate(interval time.Duration) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.averageRate(interval)
+}
+
+// CalculateAverageRateErr is like CalculateAverageRate, but returns ErrNotStarted if the counter was never started,
+// instead of a rate of 0 that can't be told apart from a counter without increments.
+func (c *Counter) CalculateAverageRateErr(interval time.Duration) (float64, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.startedAt.IsZero() {
+		return 0, ErrNotStarted
+	}
+
+	return c.averageRate(interval), nil
+}
+
+// CalculateAverageRateAt calculates the average rate of the counter like CalculateAverageRate,
+// but with the time span ending at end instead of now, so repeated calls with the same end return the same value
+// as long as there are no new increments.
+// If the counter was stopped before end, the time span ends when it was stopped.
+// It returns the rate in `count / interval`.
+func (c *Counter) CalculateAverageRateAt(end time.Time, interval time.Duration) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if until := c.until(); until.Before(end) {
+		end = until
+	}
+
+	return c.averageRateUntil(end, interval)
+}
+
+// CalculateAverageRateExact calculates the average rate of the counter like CalculateAverageRate, but as an exact fraction,
+// without the rounding of float64, even for counts above 2^53.
+// It returns the rate in `count / interval`.
+func (c *Counter) CalculateAverageRateExact(interval time.Duration) *big.Rat {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elapsed := c.elapsed()
+	if c.count <= c.rateBase || elapsed <= 0 {
+		return new(big.Rat)
+	}
+
+	rate := new(big.Rat).SetFrac(new(big.Int).SetUint64(c.count-c.rateBase), big.NewInt(int64(elapsed)))
+
+	return rate.Mul(rate, new(big.Rat).SetInt64(int64(interval)))
+}
+
+// AverageRate returns the average rate of the counter in `count / interval`, with the interval set via WithDefaultInterval.
+func (c *Counter) AverageRate() float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.averageRate(c.interval())
+}
+
+// CalculateCurrentRate calculates the current rate of the counter.
+// It returns the rate in `count / interval`.
+// The current rate is derived from an exponentially weighted moving average of the time between the most recent increments,
+// so it follows changes of the rate quickly. It decreases while there are no increments.
+// To keep the clock off the hot path of Increment, the increments between two reads of the current rate are taken as evenly spaced,
+// unless the time of each increment is needed anyway, e.g. with WithAdvancedStats.
+// It returns 0 if the counter has not been incremented since it was started.
+func (c *Counter) CalculateCurrentRate(interval time.Duration) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.currentRate(interval)
+}
+
+// CurrentRate returns the current rate of the counter in `count / interval`, with the interval set via WithDefaultInterval.
+// See CalculateCurrentRate for details.
+func (c *Counter) CurrentRate() float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.currentRate(c.interval())
+}
+
+// CalculateSmoothedRate calculates a blend of the current and the average rate of the counter, e.g. for a stable but responsive display.
+// It returns `alpha * current + (1 - alpha) * average` in `count / interval`, with alpha clamped to [0, 1].
+// An alpha of 0 returns the average rate, and an alpha of 1 the current rate. A NaN alpha is treated as 0.
+func (c *Counter) CalculateSmoothedRate(alpha float64, interval time.Duration) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if math.IsNaN(alpha) {
+		alpha = 0
+	}
+
+	alpha = min(max(alpha, 0), 1)
+
+	return alpha*c.currentRate(interval) + (1-alpha)*c.averageRate(interval)
+}
+
+// currentRate calculates the current rate of the counter.
+// It must be called with c.mutex held.
+func (c *Counter) currentRate(interval time.Duration) float64 {
+	until := c.until()
+	c.updateCurrentRate(until)
+
+	if c.lastIncrementAt.IsZero() {
+		return 0
+	}
+
+	gap := c.gapAverage
+	if idle := float64(until.Sub(c.lastIncrementAt)); idle > gap {
+		gap = idle
+	}
+
+	if gap <= 0 {
+		return 0
+	}
+
+	return float64(interval) / gap
+}
+
+// Throughput returns the average rate of the counter in `count / interval`, like CalculateAverageRate.
+// It is meant for counters that count a quantity instead of events: for example, a counter that is incremented
+// by the size of each processed item via IncrementBy reports the throughput in bytes per interval.
+func (c *Counter) Throughput(interval time.Duration) float64 {
+	return c.CalculateAverageRate(interval)
+}
+
+// RatePerSecond returns the average rate of the counter in `count / second`.
+func (c *Counter) RatePerSecond() float64 {
+	return c.CalculateAverageRate(time.Second)
+}
+
+// RatePerMinute returns the average rate of the counter in `count / minute`.
+func (c *Counter) RatePerMinute() float64 {
+	return c.CalculateAverageRate(time.Minute)
+}
+
+// RatePerHour returns the average rate of the counter in `count / hour`.
+func (c *Counter) RatePerHour() float64 {
+	return c.CalculateAverageRate(time.Hour)
+}
+
+// averageRate calculates the average rate of the counter.
+// It must be called with c.mutex held.
+func (c *Counter) averageRate(interval time.Duration) float64 {
+	return c.averageRateUntil(c.until(), interval)
+}
+
+// averageRateUntil calculates the average rate of the counter, with the time span ending at end.
+// It must be called with c.mutex held.
+func (c *Counter) averageRateUntil(end time.Time, interval time.Duration) float64 {
+	if c.count <= c.rateBase || c.startedAt.IsZero() {
+		return 0
+	}
+
+	elapsed := c.activeDuration(end)
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return float64(c.count-c.rateBase) / float64(elapsed) * float64(interval)
+}
+
+// CalculateMaximumRate calculates the maximum rate of the counter.
+// It returns the rate in `count / interval`.
+// It returns 0 if the counter has not been started yet, or if all increments were recorded at the same time.
+// On platforms where time.Now has a coarse resolution, e.g. about 0.5ms on some Windows versions, many increments
+// share a timestamp; they are spread evenly over the duration since the previous timestamp, instead of making the rate infinite.
+// Needs to be enabled via WithAdvancedStats.
+// With WithStrictStats, it panics with ErrStatsDisabled if they are not enabled.
+func (c *Counter) CalculateMaximumRate(interval time.Duration) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.checkStrictStats()
+
+	return c.maximumRate(interval)
+}
+
+// CalculateMaximumRateErr is like CalculateMaximumRate, but returns ErrStatsDisabled if advanced stats are not enabled via WithAdvancedStats.
+func (c *Counter) CalculateMaximumRateErr(interval time.Duration) (float64, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.enableStats {
+		return 0, ErrStatsDisabled
+	}
+
+	return c.maximumRate(interval), nil
+}
+
+// maximumRate calculates the maximum rate of the counter.
+// It must be called with c.mutex held.
+func (c *Counter) maximumRate(interval time.Duration) float64 {
+	if !c.enableStats {
+		return 0
+	}
+
+	min, _, ok := c.diffExtremes()
+	if !ok || min <= 0 {
+		return 0
+	}
+
+	return c.sampleWeight() * float64(interval) / float64(min)
+}
+
+// CalculateMinimumRate calculates the minimum rate of the counter.
+// It returns the rate in `count / interval`.
+// It returns 0 if the counter has not been started yet, or if all increments were recorded at the same time.
+// Needs to be enabled via WithAdvancedStats.
+// With WithStrictStats, it panics with ErrStatsDisabled if they are not enabled.
+func (c *Counter) CalculateMinimumRate(interval time.Duration) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.checkStrictStats()
+
+	return c.minimumRate(interval)
+}
+
+// CalculateMinimumRateErr is like CalculateMinimumRate, but returns ErrStatsDisabled if advanced stats are not enabled via WithAdvancedStats.
+func (c *Counter) CalculateMinimumRateErr(interval time.Duration) (float64, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.enableStats {
+		return 0, ErrStatsDisabled
+	}
+
+	return c.minimumRate(interval), nil
+}
+
+// SetMinMaxDiff seeds the shortest and longest duration between consecutive increments, e.g. from a saved counter whose triggers were not persisted.
+// CalculateMaximumRate and CalculateMinimumRate consider them together with the increments recorded from now on.
+// If either duration is not positive, the seeds are cleared. They are also cleared by Reset.
+// Needs to be enabled via WithAdvancedStats.
+func (c *Counter) SetMinMaxDiff(min, max time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if min <= 0 || max <= 0 {
+		c.seedMinDiff, c.seedMaxDiff = 0, 0
+		return
+	}
+
+	if min > max {
+		min, max = max, min
+	}
+
+	c.seedMinDiff, c.seedMaxDiff = min, max
+}
+
+// ResetExtremes clears the minimum and maximum rate, e.g. after a known outlier like a long GC pause.
+// They are calculated again from the increments from now on, while the count and the recorded increments are kept.
+// It also clears the durations set via SetMinMaxDiff.
+func (c *Counter) ResetExtremes() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.extremesFrom = c.now()
+	c.seedMinDiff = 0
+	c.seedMaxDiff = 0
+	c.lightHasDiff = false
+}
+
+// MinMaxDiff returns the shortest and longest duration between consecutive increments, including the ones set via SetMinMaxDiff.
+// Increments that share a timestamp, due to a coarse clock resolution, split the duration before it evenly.
+// They can be saved and restored via SetMinMaxDiff. It returns 0 for both if there are none.
+// Needs to be enabled via WithAdvancedStats.
+func (c *Counter) MinMaxDiff() (min, max time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.enableStats {
+		return 0, 0
+	}
+
+	min, max, _ = c.diffExtremes()
+
+	return min, max
+}
+
+// FastestInterval returns the shortest duration between consecutive increments, like the first result of MinMaxDiff,
+// e.g. for displaying "fastest gap: 2ms". It returns 0 if there is none.
+// Needs to be enabled via WithAdvancedStats.
+func (c *Counter) FastestInterval() time.Duration {
+	min, _ := c.MinMaxDiff()
+	return min
+}
+
+// SlowestInterval returns the longest duration between consecutive increments, like the second result of MinMaxDiff,
+// e.g. for displaying "slowest gap: 1.3s". It returns 0 if there is none.
+// Needs to be enabled via WithAdvancedStats.
+func (c *Counter) SlowestInterval() time.Duration {
+	_, max := c.MinMaxDiff()
+	return max
+}
+
+// minimumRate calculates the minimum rate of the counter.
+// It must be called with c.mutex held.
+func (c *Counter) minimumRate(interval time.Duration) float64 {
+	if !c.enableStats {
+		return 0
+	}
+
+	_, max, ok := c.diffExtremes()
+	if !ok || max <= 0 {
+		return 0
+	}
+
+	return c.sampleWeight() * float64(interval) / float64(max)
+}
+
+// diffExtremes returns the shortest and longest duration between consecutive recorded increments,
+// merged with the durations set via SetMinMaxDiff. ok is false if there are none.
+// It must be called with c.mutex held.
+func (c *Counter) diffExtremes() (min, max time.Duration, ok bool) {
+	min, max = c.seedMinDiff, c.seedMaxDiff
+	ok = min > 0 || max > 0
+
+	merge := func(diff time.Duration) {
+		if diff < min || !ok {
+			min = diff
+		}
+
+		if diff > max || !ok {
+			max = diff
+		}
+
+		ok = true
+	}
+
+	if c.lightStats {
+		if c.lightHasDiff {
+			merge(c.lightMinDiff)
+			merge(c.lightMaxDiff)
+		}
+
+		return min, max, ok
+	}
+
+	// after ResetExtremes, only the durations between increments recorded since then are considered
+	var ticks tickCoalescer
+
+	if c.diffStorage {
+		gaps := c.orderedGaps()
+
+		t := c.gapsFrom
+		for i := 1; i < len(gaps); i++ {
+			if !t.Before(c.extremesFrom) {
+				ticks.add(gaps[i], merge)
+			}
+
+			t = t.Add(gaps[i])
+		}
+
+		ticks.flush(merge)
+
+		return min, max, ok
+	}
+
+	triggers := c.samples()
+	i := sort.Search(len(triggers), func(i int) bool { return !triggers[i].Before(c.extremesFrom) })
+
+	for i++; i < len(triggers); i++ {
+		ticks.add(triggers[i].Sub(triggers[i-1]), merge)
+	}
+
+	ticks.flush(merge)
+
+	return min, max, ok
+}
+
+// tickCoalescer spreads the duration before a clock tick over all increments recorded at that tick.
+// On platforms where time.Now has a coarse resolution, many increments share a timestamp, so most durations between
+// consecutive increments are 0, which would make the maximum rate infinite. Instead, k increments recorded at the same
+// timestamp, d after the previous timestamp, count as k durations of d / k.
+// Increments at the first timestamp have no duration before them, and are not considered.
+type tickCoalescer struct {
+	pending time.Duration
+	shared  int
+}
+
+// add adds the next duration between consecutive increments, and passes the coalesced duration of the previous tick
+// to merge once it is complete.
+func (t *tickCoalescer) add(diff time.Duration, merge func(time.Duration)) {
+	if diff <= 0 {
+		if t.pending > 0 {
+			t.shared++
+		}
+
+		return
+	}
+
+	t.flush(merge)
+	t.pending, t.shared = diff, 1
+}
+
+// flush passes the coalesced duration of the last tick to merge, if any.
+func (t *tickCoalescer) flush(merge func(time.Duration)) {
+	if t.pending > 0 {
+		merge(t.pending / time.Duration(t.shared))
+	}
+
+	t.pending, t.shared = 0, 0
+}
+
+// tryIncrement increments the counter like increment, unless the hook set via WithPreIncrement vetoes it.
+// It reports whether the counter was incremented; the returned function must be called in either case.
+// It must be called with c.mutex held.
+func (c *Counter) tryIncrement(n uint64, t time.Time) (after func(), ok bool) {
+	if c.preIncrement != nil && !c.preIncrement(c.count) {
+		return func() {}, false
+	}
+
+	return c.increment(n, t), true
+}
+
+// increment increments the counter by n, which must not be 0, and records the increment at t, or now if t is zero.
+// It must be called with c.mutex held. The returned function runs the hooks of the increment,
+// and must be called after c.mutex is released.
+func (c *Counter) increment(n uint64, t time.Time) (after func()) {
+	// start before counting n, so that a fresh window set via WithFreshWindowOnStart includes it
+	if c.autoStart && c.state == StateNeverStarted {
+		c.start()
+	}
+
+	previous := c.count
+	if c.count > math.MaxUint64-n {
+		c.count = math.MaxUint64
+	} else {
+		c.count += n
+	}
+
+	c.totalIncremented = addSaturating(c.totalIncremented, c.count-previous)
+
+	for _, e := range c.statsD {
+		e.pending = addSaturating(e.pending, c.count-previous)
+	}
+
+	// reading the clock dominates the cost of an increment, so it is only read if a feature needs the time of the increment
+	var now time.Time
+	clock := func() time.Time {
+		if now.IsZero() {
+			now = c.now()
+		}
+
+		return now
+	}
+
+	if !t.IsZero() && c.wallClock {
+		t = t.Round(0)
+	}
+
+	at := func() time.Time {
+		if t.IsZero() {
+			return clock()
+		}
+
+		return t
+	}
+
+	// the part of n after the warm-up is included in the rates and statistics
+	measured := n
+	if c.warmedUp < c.warmup {
+		measured = c.warmUp(n, previous, clock())
+	}
+
+	if measured > 0 {
+		if c.enableStats && c.sample() {
+			c.record(at())
+		}
+
+		if c.median != nil {
+			c.median.observe(at())
+		}
+
+		if c.movingAverage != nil {
+			c.movingAverage.observe(at())
+		}
+
+		c.pendingRate = addSaturating(c.pendingRate, measured)
+	}
+
+	c.incremented(n, clock)
+
+	// with the clock read anyway, the current rate is updated right away instead of on the next read
+	if !now.IsZero() {
+		c.updateCurrentRate(now)
+	}
+
+	after = func() {}
+	if previous == 0 && c.onFirstIncrement != nil {
+		after, c.onFirstIncrement = c.onFirstIncrement, nil
+	}
+
+	if c.significant != nil && c.warmedUp >= c.warmup {
+		if rate := c.currentRate(c.interval()); c.significant.observe(rate) {
+			hooks, fn := after, c.significant.fn
+			after = func() {
+				hooks()
+				fn(rate)
+			}
+		}
+	}
+
+	if observers := c.observers; len(observers) > 0 {
+		hooks, count := after, c.count
+		after = func() {
+			hooks()
+
+			for _, o := range observers {
+				o.OnIncrement(count)
+			}
+		}
+	}
+
+	return after
+}
+
+// warmUp counts the warm-up increments of an increment by n at now, which started at the count previous.
+// After the last one, the measured time span of a running counter restarts, and the count up to it is excluded from the rates.
+// It returns the part of n after the warm-up.
+// It must be called with c.mutex held.
+func (c *Counter) warmUp(n, previous uint64, now time.Time) (rest uint64) {
+	warm := min(n, c.warmup-c.warmedUp)
+	c.warmedUp += warm
+
+	if c.warmedUp < c.warmup {
+		return 0
+	}
+
+	c.rateBase = min(addSaturating(previous, warm), c.count)
+	c.lastIncrementAt = now
+
+	if c.state == StateRunning {
+		c.startedAt = now
+		c.clearPauses()
+	}
+
+	return n - warm
+}
+
+// currentRateSmoothing is the weight of the latest time between increments in the moving average of CalculateCurrentRate.
+const currentRateSmoothing = 0.25
+
+// updateCurrentRate updates the moving average of the time between increments with the increments since the last update,
+// which are taken as evenly spaced until now.
+// The increments are only counted in increment, and included here when the current rate is read, unless the clock
+// was read for the increment anyway.
+// It must be called with c.mutex held.
+func (c *Counter) updateCurrentRate(now time.Time) {
+	n := c.pendingRate
+	if n == 0 {
+		return
+	}
+
+	c.pendingRate = 0
+
+	from := c.lastIncrementAt
+	c.lastIncrementAt = now
+
+	if from.IsZero() {
+		if c.startedAt.IsZero() {
+			return
+		}
+
+		from = c.startedAt
+	}
+
+	gap := max(float64(now.Sub(from))/float64(n), 0)
+	if c.gapAverage == 0 {
+		c.gapAverage = gap
+		n--
+	}
+
+	// the same as n updates with the same gap
+	c.gapAverage = gap + math.Pow(1-currentRateSmoothing, float64(n))*(c.gapAverage-gap)
+}
+
+// interval returns the interval set via WithDefaultInterval, or one second.
+// It must be called with c.mutex held.
+func (c *Counter) interval() time.Duration {
+	if c.defaultInterval <= 0 {
+		return time.Second
+	}
+
+	return c.defaultInterval
+}
+
+// record records an increment at t for the advanced stats, keeping the recorded increments sorted.
+// If the number of recorded increments is bounded, the oldest one is dropped.
+// It must be called with c.mutex held.
+func (c *Counter) record(t time.Time) {
+	if c.lightStats {
+		c.recordLight(t)
+		return
+	}
+
+	if c.diffStorage {
+		c.recordGap(t)
+		return
+	}
+
+	n := len(c.triggers)
+	if n > 0 && t.Before(c.triggers[c.newest()]) {
+		c.insert(t)
+		return
+	}
+
+	if c.maxSamples <= 0 {
+		c.triggers = append(c.triggers, t)
+		return
+	}
+
+	if n < c.maxSamples {
+		if n == cap(c.triggers) {
+			triggers := make([]time.Time, n, min(c.maxSamples, max(2*n, 8)))
+			copy(triggers, c.triggers)
+			c.triggers = triggers
+		}
+
+		c.triggers = append(c.triggers, t)
+
+		return
+	}
+
+	// the ring buffer is full, overwrite the oldest increment
+	c.triggers[c.ringHead] = t
+	c.ringHead = (c.ringHead + 1) % n
+}
+
+// insert inserts an increment that is older than the newest one at its chronological position.
+// It must be called with c.mutex held.
+func (c *Counter) insert(t time.Time) {
+	triggers := c.samples()
+	n := len(triggers)
+
+	if c.maxSamples > 0 && n >= c.maxSamples {
+		if t.Before(triggers[0]) {
+			return
+		}
+
+		// drop the oldest increment to make room
+		copy(triggers, triggers[1:])
+		triggers = triggers[:n-1]
+		n--
+	} else if c.maxSamples > 0 && n == cap(triggers) {
+		// grow like record does, without exceeding the bound
+		grown := make([]time.Time, n, min(c.maxSamples, max(2*n, 8)))
+		copy(grown, triggers)
+		triggers = grown
+	}
+
+	i := sort.Search(n, func(i int) bool { return triggers[i].After(t) })
+	triggers = append(triggers, time.Time{})
+	copy(triggers[i+1:], triggers[i:])
+	triggers[i] = t
+	c.setSamples(triggers)
+}
+
+// newest returns the index of the newest recorded increment.
+// It must be called with c.mutex held.
+func (c *Counter) newest() int {
+	if c.ringHead == 0 {
+		return len(c.triggers) - 1
+	}
+
+	return c.ringHead - 1
+}
+
+// samples returns the recorded increments in chronological order.
+// With diff storage, they are reconstructed into a new slice.
+// If the ring buffer of a bounded counter wrapped around, it is rotated in place.
+// It must be called with c.mutex held.
+func (c *Counter) samples() []time.Time {
+	if c.diffStorage {
+		return c.decodeGaps()
+	}
+
+	if c.ringHead != 0 {
+		slices.Reverse(c.triggers[:c.ringHead])
+		slices.Reverse(c.triggers[c.ringHead:])
+		slices.Reverse(c.triggers)
+		c.ringHead = 0
+	}
+
+	return c.triggers
+}
+
+// sample reports whether the current increment should be recorded by the advanced stats.
+// It must be called with c.mutex held.
+func (c *Counter) sample() bool {
+	if c.sampling == 0 {
+		return true
+	}
+
+	c.sampleTick++
+
+	return c.sampleTick%c.sampling == 1
+}
+
+// sampleWeight returns the number of increments a single recorded increment represents.
+// It must be called with c.mutex held.
+func (c *Counter) sampleWeight() float64 {
+	if c.sampling == 0 {
+		return 1
+	}
+
+	return float64(c.sampling)
+}
+
+// checkStrictStats panics with ErrStatsDisabled, if WithStrictStats is set and advanced stats are not enabled.
+// It must be called with c.mutex held.
+func (c *Counter) checkStrictStats() {
+	if c.strictStats && !c.enableStats {
+		panic(ErrStatsDisabled)
+	}
+}
+
+// finite returns f, or 0 if f is NaN or infinite, so that degenerate inputs can't poison downstream aggregations.
+func finite(f float64) float64 {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0
+	}
+
+	return f
+}
+
+// addSaturating returns a + b, or the maximum uint64 value if the sum overflows.
+func addSaturating(a, b uint64) uint64 {
+	if a > math.MaxUint64-b {
+		return math.MaxUint64
+	}
+
+	return a + b
+}
+
+// dropped checks whether the count decreased from previous, if WithMonotonicCheck is enabled.
+// It must be called with c.mutex held. The returned function runs the regression callback,
+// and must be called after c.mutex is released.
+func (c *Counter) dropped(previous uint64) (after func()) {
+	if !c.monotonicCheck || c.count >= previous {
+		return func() {}
+	}
+
+	c.regressed = true
+
+	if c.onRegression == nil {
+		return func() {}
+	}
+
+	onRegression, current := c.onRegression, c.count
+
+	return func() { onRegression(previous, current) }
+}
+
+// incremented updates the rolling buckets, sends the current count to all channels registered via EmitTo without blocking,
+// and triggers all increment callbacks, after the counter was incremented by n.
+// It must be called with c.mutex held.
+func (c *Counter) incremented(n uint64, clock func() time.Time) {
+	if c.rolling != nil {
+		c.rolling.add(clock(), n)
+	}
+
+	for _, ch := range c.emitTo {
+		select {
+		case ch <- c.count:
+		default:
+		}
+	}
+
+	for _, cb := range c.callbacks {
+		cb.trigger(clock())
+	}
+}
+
+// log logs a lifecycle event, if a logger is set via WithLogger.
+// It must be called with c.mutex held.
+func (c *Counter) log(msg string) {
+	if c.logger == nil {
+		return
+	}
+
+	c.logger.LogAttrs(context.Background(), slog.LevelDebug, msg, slog.Uint64("count", c.count))
+}
+
+// now returns the current time of the clock set via WithClock, or of the system clock.
+// With WithWallClock, the monotonic clock reading is stripped.
+func (c *Counter) now() time.Time {
+	var t time.Time
+	if c.clock != nil {
+		t = c.clock.Now()
+	} else {
+		t = time.Now()
+	}
+
+	if c.wallClock {
+		return t.Round(0)
+	}
+
+	return t
+}
+
+// afterFunc calls f after d, measured by the clock set via WithClock if it is a TimerClock,
+// or by the system clock.
+// It must be called with c.mutex held.
+func (c *Counter) afterFunc(d time.Duration, f func()) Timer {
+	if clock, ok := c.clock.(TimerClock); ok {
+		return clock.AfterFunc(d, f)
+	}
+
+	return time.AfterFunc(d, f)
+}
+
+// elapsed returns the measured time span of the counter, excluding pauses.
+// It is never negative, even if the clock jumped backwards; with the system clock, Go uses monotonic clock readings for it anyway.
+// It must be called with c.mutex held.
+func (c *Counter) elapsed() time.Duration {
+	return c.activeDuration(c.until())
+}
+
+// until returns the end of the measured time span, which is the time the counter was stopped, or now otherwise.
+// It must be called with c.mutex held.
+func (c *Counter) until() time.Time {
+	if c.state != StateStopped {
+		return c.now()
 	}
 
-	return float64(interval) / float64(max)
+	return c.stoppedAt
 }
@@ -1,8 +1,14 @@
 package counter
 
 import (
+	"math"
+	"math/big"
+	"runtime"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/MarvinJWendt/testza"
 )
@@ -50,6 +56,1220 @@ func TestCounter(t *testing.T) {
 	})
 }
 
+// fakeClock is a TimerClock that only advances when told to.
+// Calls scheduled via AfterFunc are made by Advance once they are due, in the goroutine that called Advance.
+type fakeClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// fakeTimer is a call scheduled on a fakeClock.
+type fakeTimer struct {
+	clock *fakeClock
+	due   time.Time
+	f     func()
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1_000_000, 0)}
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.now
+}
+
+// Advance moves the clock forward by d, and makes all calls that are due by then, in the order they are due.
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mutex.Lock()
+	f.now = f.now.Add(d)
+	f.mutex.Unlock()
+
+	for {
+		f.mutex.Lock()
+
+		var next *fakeTimer
+		for _, t := range f.timers {
+			if !t.due.After(f.now) && (next == nil || t.due.Before(next.due)) {
+				next = t
+			}
+		}
+
+		if next == nil {
+			f.mutex.Unlock()
+			return
+		}
+
+		f.timers = slices.DeleteFunc(f.timers, func(t *fakeTimer) bool { return t == next })
+		f.mutex.Unlock()
+
+		next.f()
+	}
+}
+
+// untilNext returns the duration until the next scheduled call is due. ok is false if there is none.
+func (f *fakeClock) untilNext() (d time.Duration, ok bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for _, t := range f.timers {
+		if due := t.due.Sub(f.now); !ok || due < d {
+			d, ok = due, true
+		}
+	}
+
+	return d, ok
+}
+
+// waitTimers blocks until at least n calls are scheduled, e.g. by a worker that was just started.
+func (f *fakeClock) waitTimers(n int) {
+	for {
+		f.mutex.Lock()
+		scheduled := len(f.timers)
+		f.mutex.Unlock()
+
+		if scheduled >= n {
+			return
+		}
+
+		runtime.Gosched()
+	}
+}
+
+func (f *fakeClock) AfterFunc(d time.Duration, fn func()) Timer {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	t := &fakeTimer{clock: f, due: f.now.Add(d), f: fn}
+	f.timers = append(f.timers, t)
+
+	return t
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+
+	n := len(t.clock.timers)
+	t.clock.timers = slices.DeleteFunc(t.clock.timers, func(other *fakeTimer) bool { return other == t })
+
+	return len(t.clock.timers) < n
+}
+
+func TestCounter_WithAdvancedStats(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock)
+	c.IncrementBy(3)
+
+	testza.AssertEqual(t, c, c.WithAdvancedStats())
+	testza.AssertEqual(t, uint64(3), c.Count())
+
+	c.Start()
+	clock.Advance(time.Second)
+	c.Increment()
+	clock.Advance(time.Second)
+	c.Increment()
+
+	testza.AssertEqual(t, 1.0, c.CalculateMaximumRate(time.Second))
+}
+
+func TestCounter_CalculateCurrentRate(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).Start()
+	testza.AssertEqual(t, 0.0, c.CalculateCurrentRate(time.Second))
+
+	for i := 0; i < 20; i++ {
+		clock.Advance(100 * time.Millisecond)
+		c.Increment()
+	}
+
+	testza.AssertInRange(t, c.CalculateCurrentRate(time.Second), 10-1e-9, 10+1e-9)
+
+	// the rate picks up quickly
+	for i := 0; i < 20; i++ {
+		clock.Advance(10 * time.Millisecond)
+		c.Increment()
+	}
+
+	testza.AssertInRange(t, c.CalculateCurrentRate(time.Second), 95.0, 100.0)
+	testza.AssertLess(t, c.CalculateAverageRate(time.Second), 20.0)
+
+	// and decreases while idle
+	clock.Advance(time.Second)
+	testza.AssertEqual(t, 1.0, c.CalculateCurrentRate(time.Second))
+
+	c.Reset()
+	testza.AssertEqual(t, 0.0, c.CalculateCurrentRate(time.Second))
+}
+
+func TestCounter_CalculateCurrentRate_Unread(t *testing.T) {
+	clock := newFakeClock()
+	plain := NewCounter().WithClock(clock).Start()
+	stats := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+
+	for i := 0; i < 10; i++ {
+		clock.Advance(900 * time.Millisecond)
+		plain.Increment()
+		stats.Increment()
+	}
+
+	for i := 0; i < 10; i++ {
+		clock.Advance(100 * time.Millisecond)
+		plain.Increment()
+		stats.Increment()
+	}
+
+	// without a read in between, the increments of the counter are taken as evenly spaced
+	testza.AssertInRange(t, plain.CalculateCurrentRate(time.Second), 2-1e-9, 2+1e-9)
+	// while the time of each increment is known with advanced stats
+	testza.AssertGreater(t, stats.CalculateCurrentRate(time.Second), 5.0)
+}
+
+func TestCounter_FastestAndSlowestInterval(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+	c.Increment()
+	testza.AssertEqual(t, time.Duration(0), c.FastestInterval())
+	testza.AssertEqual(t, time.Duration(0), c.SlowestInterval())
+
+	for _, d := range []time.Duration{40, 2, 1300, 15} {
+		clock.Advance(d * time.Millisecond)
+		c.Increment()
+	}
+
+	testza.AssertEqual(t, 2*time.Millisecond, c.FastestInterval())
+	testza.AssertEqual(t, 1300*time.Millisecond, c.SlowestInterval())
+	testza.AssertEqual(t, time.Duration(0), NewCounter().FastestInterval())
+}
+
+func TestCounter_CoarseClockResolution(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+	light := NewCounter().WithClock(clock).WithLightStats().Start()
+
+	// ten increments per clock tick of 15ms
+	for tick := 0; tick < 5; tick++ {
+		clock.Advance(15 * time.Millisecond)
+
+		for i := 0; i < 10; i++ {
+			c.Increment()
+			light.Increment()
+		}
+	}
+
+	testza.AssertInRange(t, c.CalculateMaximumRate(time.Second), 666.0, 667.0)
+	testza.AssertInRange(t, c.CalculateMinimumRate(time.Second), 666.0, 667.0)
+	testza.AssertInRange(t, c.WindowedMaxRate(time.Hour, time.Second), 666.0, 667.0)
+
+	min, max := c.MinMaxDiff()
+	testza.AssertEqual(t, 1500*time.Microsecond, min)
+	testza.AssertEqual(t, 1500*time.Microsecond, max)
+
+	// light stats don't keep the increments, so they only skip the shared timestamps
+	testza.AssertInRange(t, light.CalculateMaximumRate(time.Second), 66.0, 67.0)
+}
+
+func TestCounter_RatesAreFinite(t *testing.T) {
+	for name, build := range map[string]func(clock *fakeClock) *Counter{
+		"ZeroElapsed": func(clock *fakeClock) *Counter {
+			c := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+			c.IncrementBy(5)
+
+			return c
+		},
+		"OneTrigger": func(clock *fakeClock) *Counter {
+			c := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+			clock.Advance(time.Second)
+			c.Increment()
+
+			return c
+		},
+		"ZeroDiff": func(clock *fakeClock) *Counter {
+			c := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+			clock.Advance(time.Second)
+			c.Increment()
+			c.Increment()
+
+			return c
+		},
+		"HugeCount": func(clock *fakeClock) *Counter {
+			c := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+			c.MarkReference()
+			clock.Advance(time.Nanosecond)
+			c.IncrementBy(math.MaxUint64)
+
+			return c
+		},
+		"NonFiniteBaseline": func(clock *fakeClock) *Counter {
+			c := NewCounter().WithClock(clock).WithBaseline(math.Inf(1), time.Second).Start()
+			clock.Advance(time.Second)
+			c.Increment()
+
+			return c
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := build(newFakeClock())
+			interval := time.Duration(math.MaxInt64)
+
+			for _, v := range []float64{
+				c.CalculateAverageRate(interval),
+				c.CalculateCurrentRate(interval),
+				c.CalculateSmoothedRate(math.NaN(), interval),
+				c.CalculateMinimumRate(interval),
+				c.CalculateMaximumRate(interval),
+				c.CalculateCoefficientOfVariation(),
+				c.CalculatePercentileRate(100, interval),
+				c.TrimmedMeanRate(0.1, interval),
+				c.WindowedMinRate(time.Hour, interval),
+				c.WindowedMaxRate(time.Hour, interval),
+				c.CalculateRateSinceMark(interval),
+				c.DeviationFromBaseline(),
+			} {
+				testza.AssertFalse(t, math.IsNaN(v) || math.IsInf(v, 0), v)
+			}
+		})
+	}
+}
+
+func TestCounter_WithFreshWindowOnStart(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithFreshWindowOnStart()
+
+	c.Start()
+	clock.Advance(time.Second)
+	c.IncrementBy(10)
+	c.Stop()
+	testza.AssertEqual(t, 10.0, c.CalculateAverageRate(time.Second))
+
+	clock.Advance(time.Hour)
+	c.Start()
+	clock.Advance(2 * time.Second)
+	c.IncrementBy(2)
+	c.Stop()
+
+	testza.AssertEqual(t, uint64(12), c.Count())
+	testza.AssertEqual(t, 1.0, c.CalculateAverageRate(time.Second))
+}
+
+func TestCounter_WithPreIncrement(t *testing.T) {
+	c := NewCounter().WithPreIncrement(func(current uint64) bool { return current < 3 }).Start()
+
+	for i := 0; i < 3; i++ {
+		testza.AssertTrue(t, c.TryIncrement())
+	}
+
+	testza.AssertFalse(t, c.TryIncrement())
+	testza.AssertFalse(t, c.IncrementIfRunning())
+	c.Increment()
+	c.IncrementBy(10)
+	c.IncrementTagged("vetoed")
+	testza.AssertEqual(t, uint64(3), c.Count())
+	testza.AssertEqual(t, map[string]uint64{}, c.Breakdown())
+
+	c.Decrement()
+	testza.AssertTrue(t, c.TryIncrement())
+	testza.AssertEqual(t, uint64(3), c.Count())
+
+	c.WithPreIncrement(nil)
+	c.Increment()
+	testza.AssertEqual(t, uint64(4), c.Count())
+}
+
+func TestCounter_WithMetadata(t *testing.T) {
+	metadata := map[string]string{"env": "prod", "service": "api"}
+	c := NewCounter().WithMetadata(metadata)
+	metadata["env"] = "dev"
+
+	testza.AssertEqual(t, map[string]string{"env": "prod", "service": "api"}, c.Metadata())
+	testza.AssertEqual(t, c.Metadata(), c.Snapshot(time.Second).Metadata)
+
+	c.Metadata()["env"] = "dev"
+	testza.AssertEqual(t, "prod", c.Metadata()["env"])
+
+	var clone Counter
+	clone.CopyFrom(c)
+	testza.AssertEqual(t, c.Metadata(), clone.Metadata())
+
+	merged := NewCounter().WithMetadata(map[string]string{"env": "staging"})
+	testza.AssertNoError(t, merged.MergeStats(c))
+	testza.AssertEqual(t, map[string]string{"env": "staging", "service": "api"}, merged.Metadata())
+	testza.AssertEqual(t, map[string]string{"env": "prod", "service": "api"}, clone.Metadata())
+
+	testza.AssertNil(t, NewCounter().Metadata())
+}
+
+func TestCounter_WithAutoStart(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithAutoStart()
+	testza.AssertEqual(t, StateNeverStarted, c.State())
+
+	c.Increment()
+	testza.AssertEqual(t, StateRunning, c.State())
+
+	clock.Advance(time.Second)
+	c.IncrementBy(9)
+	testza.AssertEqual(t, 10.0, c.CalculateAverageRate(time.Second))
+
+	c.Stop()
+	c.Increment()
+	testza.AssertEqual(t, StateStopped, c.State())
+
+	c.Reset()
+	c.Increment()
+	testza.AssertEqual(t, StateRunning, c.State())
+	c.Stop()
+}
+
+func TestCounter_WithAutoStart_FreshWindow(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithAutoStart().WithFreshWindowOnStart()
+
+	// the increment that starts the counter belongs to the fresh window
+	c.IncrementBy(5)
+	clock.Advance(time.Second)
+	c.IncrementBy(5)
+	testza.AssertEqual(t, 10.0, c.CalculateAverageRate(time.Second))
+
+	c.Reset()
+	c.IncrementBy(3)
+	clock.Advance(time.Second)
+	testza.AssertEqual(t, 3.0, c.CalculateAverageRate(time.Second))
+	testza.AssertEqual(t, uint64(3), c.Count())
+}
+
+func TestCounter_ResetAt(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock)
+
+	window := clock.Now().Truncate(time.Minute).Add(time.Minute)
+
+	for i := 0; i < 3; i++ {
+		// roll over a little late each time
+		clock.Advance(window.Add(500 * time.Millisecond).Sub(clock.Now()))
+		c.ResetAt(window)
+		testza.AssertEqual(t, StateRunning, c.State())
+		testza.AssertEqual(t, uint64(0), c.Count())
+
+		c.IncrementBy(120)
+		clock.Advance(window.Add(time.Minute).Sub(clock.Now()))
+		testza.AssertEqual(t, 2.0, c.CalculateAverageRate(time.Second))
+
+		window = window.Add(time.Minute)
+	}
+}
+
+func TestCounter_WithRateCarryOver(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithRateCarryOver().Start()
+
+	for i := 0; i < 20; i++ {
+		clock.Advance(100 * time.Millisecond)
+		c.Increment()
+	}
+
+	c.ResetKeepRunning()
+	testza.AssertEqual(t, uint64(0), c.Count())
+	testza.AssertInRange(t, c.CalculateCurrentRate(time.Second), 10-1e-9, 10+1e-9)
+
+	clock.Advance(100 * time.Millisecond)
+	c.Increment()
+	testza.AssertInRange(t, c.CalculateCurrentRate(time.Second), 10-1e-9, 10+1e-9)
+
+	c.Reset()
+	testza.AssertInRange(t, c.CalculateCurrentRate(time.Second), 10-1e-9, 10+1e-9)
+}
+
+func TestCounter_CalculateSmoothedRate(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).Start()
+
+	for i := 0; i < 10; i++ {
+		clock.Advance(time.Second)
+		c.Increment()
+	}
+
+	// the increments between two reads of the current rate are taken as evenly spaced
+	testza.AssertInRange(t, c.CalculateCurrentRate(time.Second), 1-1e-9, 1+1e-9)
+
+	for i := 0; i < 10; i++ {
+		clock.Advance(100 * time.Millisecond)
+		c.Increment()
+	}
+
+	average := c.CalculateAverageRate(time.Second)
+	current := c.CalculateCurrentRate(time.Second)
+	testza.AssertNotEqual(t, average, current)
+
+	testza.AssertEqual(t, average, c.CalculateSmoothedRate(0, time.Second))
+	testza.AssertEqual(t, current, c.CalculateSmoothedRate(1, time.Second))
+	testza.AssertInRange(t, c.CalculateSmoothedRate(0.5, time.Second), average, current)
+	testza.AssertEqual(t, current, c.CalculateSmoothedRate(2, time.Second))
+}
+
+func TestCounter_WithDefaultInterval(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).Start()
+
+	for i := 0; i < 10; i++ {
+		clock.Advance(time.Second)
+		c.Increment()
+	}
+
+	testza.AssertEqual(t, c.CalculateAverageRate(time.Second), c.AverageRate())
+	testza.AssertEqual(t, c.CalculateCurrentRate(time.Second), c.CurrentRate())
+
+	c.WithDefaultInterval(time.Minute)
+	testza.AssertEqual(t, c.CalculateAverageRate(time.Minute), c.AverageRate())
+	testza.AssertEqual(t, c.CalculateCurrentRate(time.Minute), c.CurrentRate())
+	testza.AssertInRange(t, c.AverageRate(), 60-1e-9, 60+1e-9)
+}
+
+func TestCounter_SetMinMaxDiff(t *testing.T) {
+	clock := newFakeClock()
+	original := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+
+	for _, d := range []time.Duration{100, 10, 500, 50} {
+		clock.Advance(d * time.Millisecond)
+		original.Increment()
+	}
+
+	// save without the triggers
+	count := original.Count()
+	min, max := original.MinMaxDiff()
+	testza.AssertEqual(t, 10*time.Millisecond, min)
+	testza.AssertEqual(t, 500*time.Millisecond, max)
+
+	restored := NewCounter().WithClock(clock).WithAdvancedStats()
+	restored.Set(count)
+	restored.SetMinMaxDiff(min, max)
+
+	testza.AssertEqual(t, original.CalculateMaximumRate(time.Second), restored.CalculateMaximumRate(time.Second))
+	testza.AssertEqual(t, original.CalculateMinimumRate(time.Second), restored.CalculateMinimumRate(time.Second))
+
+	// new increments are merged with the seeds
+	restored.Start()
+
+	for i := 0; i < 3; i++ {
+		clock.Advance(time.Millisecond)
+		restored.Increment()
+	}
+
+	testza.AssertEqual(t, 1000.0, restored.CalculateMaximumRate(time.Second))
+	testza.AssertEqual(t, 2.0, restored.CalculateMinimumRate(time.Second))
+
+	restored.SetMinMaxDiff(0, 0)
+	testza.AssertEqual(t, 1.0, restored.CalculateMaximumRate(time.Millisecond))
+	testza.AssertEqual(t, 1.0, restored.CalculateMinimumRate(time.Millisecond))
+
+	restored.Reset()
+	testza.AssertEqual(t, 0.0, restored.CalculateMaximumRate(time.Second))
+}
+
+func TestCounter_WithMonotonicCheck(t *testing.T) {
+	var regressions [][2]uint64
+
+	c := NewCounter().WithMonotonicCheck(func(previous, current uint64) {
+		regressions = append(regressions, [2]uint64{previous, current})
+	})
+
+	c.IncrementBy(10)
+	c.Set(20)
+	c.Add(0)
+	c.Reset()
+	testza.AssertFalse(t, c.HadRegression())
+
+	c.IncrementBy(10)
+	c.Decrement()
+	testza.AssertTrue(t, c.HadRegression())
+
+	c.Set(5)
+	c.DecrementBy(100)
+	c.Decrement() // already 0
+	testza.AssertEqual(t, [][2]uint64{{10, 9}, {9, 5}, {5, 0}}, regressions)
+
+	unchecked := NewCounter()
+	unchecked.Increment()
+	unchecked.Decrement()
+	testza.AssertFalse(t, unchecked.HadRegression())
+}
+
+func TestCounter_TotalIncrementedAndDecremented(t *testing.T) {
+	c := NewCounter()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				c.IncrementBy(3)
+				c.Decrement()
+				c.Add(-1)
+				c.Done()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	testza.AssertEqual(t, uint64(3000), c.TotalIncremented())
+	testza.AssertEqual(t, uint64(3000), c.TotalDecremented())
+	testza.AssertEqual(t, uint64(0), c.Count())
+
+	c.IncrementBy(5)
+	c.DecrementBy(10) // only 5 are removed
+	c.Set(100)        // not included
+	testza.AssertEqual(t, uint64(3005), c.TotalIncremented())
+	testza.AssertEqual(t, uint64(3005), c.TotalDecremented())
+
+	c.Reset()
+	testza.AssertEqual(t, uint64(0), c.TotalIncremented())
+	testza.AssertEqual(t, uint64(0), c.TotalDecremented())
+}
+
+func TestCounter_WithWarmup(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithAdvancedStats().WithWarmup(3).Start()
+
+	// slow warm-up
+	for i := 0; i < 3; i++ {
+		clock.Advance(time.Second)
+		c.Increment()
+	}
+
+	testza.AssertEqual(t, 0.0, c.CalculateAverageRate(time.Second))
+	testza.AssertEqual(t, 0, c.SampleCount())
+
+	for i := 0; i < 10; i++ {
+		clock.Advance(10 * time.Millisecond)
+		c.Increment()
+	}
+
+	testza.AssertEqual(t, uint64(13), c.Count())
+	testza.AssertEqual(t, 100.0, c.CalculateMinimumRate(time.Second))
+	testza.AssertEqual(t, 100.0, c.CalculateMaximumRate(time.Second))
+	testza.AssertInRange(t, c.CalculateAverageRate(time.Second), 100-1e-9, 100+1e-9)
+	testza.AssertInRange(t, c.CalculateCurrentRate(time.Second), 100-1e-9, 100+1e-9)
+
+	// the warm-up starts again after a reset
+	c.ResetKeepRunning()
+	clock.Advance(time.Second)
+	c.IncrementBy(3)
+	testza.AssertEqual(t, 0.0, c.CalculateAverageRate(time.Second))
+	clock.Advance(time.Second)
+	c.Increment()
+	testza.AssertEqual(t, 1.0, c.CalculateAverageRate(time.Second))
+}
+
+func TestCounter_WithWarmup_IncrementByCrossesBoundary(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithAdvancedStats().WithWarmup(5).Start()
+
+	clock.Advance(time.Second)
+	c.IncrementBy(3)
+
+	// 2 warm-up increments, and 4 after the warm-up
+	clock.Advance(time.Second)
+	c.IncrementBy(6)
+	testza.AssertEqual(t, 1, c.SampleCount())
+
+	clock.Advance(time.Second)
+	c.IncrementBy(6)
+
+	testza.AssertEqual(t, uint64(15), c.Count())
+	testza.AssertEqual(t, 10.0, c.CalculateAverageRate(time.Second))
+	testza.AssertEqual(t, 2, c.SampleCount())
+}
+
+func TestCounter_ResetExtremes(t *testing.T) {
+	for _, mode := range []string{"timestamps", "diffs", "light"} {
+		clock := newFakeClock()
+
+		c := NewCounter().WithClock(clock).WithAdvancedStats()
+		switch mode {
+		case "diffs":
+			c.WithDiffStorage()
+		case "light":
+			c.WithLightStats()
+		}
+
+		c.Start()
+
+		for i := 0; i < 5; i++ {
+			clock.Advance(100 * time.Millisecond)
+			c.Increment()
+		}
+
+		// an outlier, like a long GC pause
+		clock.Advance(10 * time.Second)
+		c.Increment()
+		c.SetMinMaxDiff(time.Millisecond, time.Minute)
+		testza.AssertEqual(t, 1000.0, c.CalculateMaximumRate(time.Second), mode)
+
+		c.ResetExtremes()
+		testza.AssertEqual(t, 0.0, c.CalculateMinimumRate(time.Second), mode)
+		testza.AssertEqual(t, 0.0, c.CalculateMaximumRate(time.Second), mode)
+		testza.AssertEqual(t, uint64(6), c.Count(), mode)
+		testza.AssertEqual(t, 6, c.SampleCount(), mode)
+
+		for i := 0; i < 5; i++ {
+			clock.Advance(200 * time.Millisecond)
+			c.Increment()
+		}
+
+		testza.AssertEqual(t, 5.0, c.CalculateMinimumRate(time.Second), mode)
+		testza.AssertEqual(t, 5.0, c.CalculateMaximumRate(time.Second), mode)
+	}
+}
+
+func TestCounter_WithStrictStats(t *testing.T) {
+	lenient := NewCounter().Start()
+	lenient.Increment()
+	testza.AssertEqual(t, 0.0, lenient.CalculateMaximumRate(time.Second))
+	testza.AssertEqual(t, 0.0, lenient.CalculateMinimumRate(time.Second))
+
+	_, err := lenient.CalculateMaximumRateErr(time.Second)
+	testza.AssertErrorIs(t, err, ErrStatsDisabled)
+	_, err = lenient.CalculateMinimumRateErr(time.Second)
+	testza.AssertErrorIs(t, err, ErrStatsDisabled)
+
+	strict := NewCounter().WithStrictStats().Start()
+	testza.AssertPanics(t, func() { strict.CalculateMaximumRate(time.Second) })
+	testza.AssertPanics(t, func() { strict.CalculateMinimumRate(time.Second) })
+
+	// the counter is still usable after the panic
+	strict.WithAdvancedStats()
+	strict.Increment()
+	testza.AssertEqual(t, 0.0, strict.CalculateMaximumRate(time.Second))
+
+	rate, err := strict.CalculateMaximumRateErr(time.Second)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, 0.0, rate)
+}
+
+func TestCounter_CalculateAverageRateAt(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).Start()
+	c.IncrementBy(10)
+	clock.Advance(time.Second)
+
+	first := c.CalculateAverageRate(time.Second)
+	clock.Advance(time.Second)
+	second := c.CalculateAverageRate(time.Second)
+	testza.AssertEqual(t, 10.0, first)
+	testza.AssertEqual(t, 5.0, second)
+
+	end := clock.Now()
+	clock.Advance(time.Second)
+	testza.AssertEqual(t, 5.0, c.CalculateAverageRateAt(end, time.Second))
+	testza.AssertEqual(t, 5.0, c.CalculateAverageRateAt(end, time.Second))
+
+	c.Stop()
+	testza.AssertEqual(t, c.CalculateAverageRate(time.Second), c.CalculateAverageRateAt(end.Add(time.Hour), time.Second))
+}
+
+func TestCounter_CalculateAverageRateExact(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).Start()
+	testza.AssertEqual(t, "0", c.CalculateAverageRateExact(time.Second).RatString())
+
+	clock.Advance(3 * time.Second)
+	c.Set(1<<53 + 1)
+
+	exact := c.CalculateAverageRateExact(3 * time.Second)
+	testza.AssertEqual(t, "9007199254740993", exact.RatString())
+
+	// the float64 rate can't represent the count exactly, but is accurate within its precision
+	rate := c.CalculateAverageRate(3 * time.Second)
+	testza.AssertNotEqual(t, "9007199254740993", new(big.Rat).SetFloat64(rate).RatString())
+
+	f, _ := exact.Float64()
+	testza.AssertInRange(t, rate, f*(1-1e-15), f*(1+1e-15))
+
+	testza.AssertEqual(t, "3002399751580331/1", c.CalculateAverageRateExact(time.Second).String())
+}
+
+func TestCounter_Throughput(t *testing.T) {
+	clock := newFakeClock()
+	bytes := NewCounter().WithClock(clock).Start()
+
+	for _, size := range []uint64{1 << 20, 3 << 20, 512 << 10, 1536 << 10} {
+		clock.Advance(250 * time.Millisecond)
+		bytes.IncrementBy(size)
+	}
+
+	testza.AssertEqual(t, 6.0*(1<<20), bytes.Throughput(time.Second))
+	testza.AssertEqual(t, bytes.CalculateAverageRate(time.Minute), bytes.Throughput(time.Minute))
+	testza.AssertEqual(t, 6.0, bytes.Throughput(time.Second)/(1<<20)) // MiB/s
+}
+
+func TestCounter_RatePer(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).Start()
+
+	clock.Advance(3 * time.Second)
+	c.IncrementBy(7)
+	c.Stop()
+
+	perSecond := c.RatePerSecond()
+	testza.AssertInRange(t, perSecond, 7.0/3-1e-9, 7.0/3+1e-9)
+	testza.AssertInRange(t, c.RatePerMinute(), perSecond*60-1e-9, perSecond*60+1e-9)
+	testza.AssertInRange(t, c.RatePerHour(), perSecond*3600-1e-9, perSecond*3600+1e-9)
+}
+
+func TestCounter_clockJumpsBackwards(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).Start()
+
+	clock.Advance(time.Second)
+	c.IncrementBy(10)
+	testza.AssertEqual(t, 10.0, c.CalculateAverageRate(time.Second))
+
+	clock.Advance(-time.Hour)
+	testza.AssertEqual(t, 0.0, c.CalculateAverageRate(time.Second))
+	testza.AssertEqual(t, time.Duration(0), c.Snapshot(time.Second).Elapsed)
+
+	c.Stop()
+	testza.AssertEqual(t, 0.0, c.CalculateAverageRate(time.Second))
+}
+
+func TestCounter_WithWallClock(t *testing.T) {
+	for _, wall := range []bool{false, true} {
+		// readings of time.Now, with a monotonic clock reading
+		clock := &fakeClock{now: time.Now()}
+
+		c := NewCounter().WithClock(clock).WithAdvancedStats()
+		if wall {
+			c.WithWallClock()
+		}
+
+		c.Start()
+		clock.Advance(time.Second)
+		c.IncrementBy(10)
+
+		// the system clock is set forward by an hour: the monotonic clock readings are not affected,
+		// while the wall clock readings, which are all the counter uses with the wall clock, jump
+		clock.mutex.Lock()
+		if wall {
+			clock.now = clock.now.Round(0).Add(time.Hour)
+		}
+		clock.now = clock.now.Add(time.Second)
+		clock.mutex.Unlock()
+		c.Increment()
+		c.Stop()
+
+		if wall {
+			testza.AssertEqual(t, time.Hour+2*time.Second, c.Snapshot(time.Second).Elapsed)
+			testza.AssertLess(t, c.CalculateAverageRate(time.Second), 1.0)
+			testza.AssertEqual(t, c.triggers[1], c.triggers[1].Round(0))
+		} else {
+			testza.AssertEqual(t, 2*time.Second, c.Snapshot(time.Second).Elapsed)
+			testza.AssertEqual(t, 5.5, c.CalculateAverageRate(time.Second))
+			testza.AssertEqual(t, 1.0, c.CalculateMaximumRate(time.Second))
+		}
+	}
+}
+
+func TestCounter_WithSampling(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithAdvancedStats().WithSampling(10).Start()
+
+	for i := 0; i < 1000; i++ {
+		clock.Advance(time.Duration(10+i%3) * time.Millisecond)
+		c.Increment()
+	}
+
+	testza.AssertEqual(t, uint64(1000), c.Count())
+	testza.AssertLen(t, c.triggers, 100)
+	testza.AssertInRange(t, c.CalculateAverageRate(time.Second), 90.0, 100.0)
+	testza.AssertInRange(t, c.CalculateMinimumRate(time.Second), 85.0, 100.0)
+	testza.AssertInRange(t, c.CalculateMaximumRate(time.Second), 85.0, 100.0)
+}
+
+func TestCounter_EmitTo(t *testing.T) {
+	ch := make(chan uint64, 10)
+	c := NewCounter().EmitTo(ch).Start()
+
+	for i := 0; i < 5; i++ {
+		c.Increment()
+	}
+	c.IncrementBy(10)
+
+	for i := 0; i < 20; i++ {
+		c.Increment()
+	}
+
+	close(ch)
+
+	var emitted []uint64
+	for v := range ch {
+		emitted = append(emitted, v)
+	}
+
+	testza.AssertLen(t, emitted, 10)
+	testza.AssertEqual(t, []uint64{1, 2, 3, 4, 5, 15}, emitted[:6])
+	testza.AssertIncreasing(t, emitted)
+}
+
+func TestCounter_IncrementIfRunning(t *testing.T) {
+	c := NewCounter()
+	testza.AssertFalse(t, c.IncrementIfRunning())
+	testza.AssertEqual(t, uint64(0), c.Count())
+
+	c.Start()
+
+	var wg sync.WaitGroup
+	stopped := make(chan struct{})
+	results := make(chan uint64, 4)
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			var afterStop uint64
+			for {
+				select {
+				case <-stopped:
+					// the stop has been observed, so no increment may succeed anymore
+					for j := 0; j < 100; j++ {
+						if c.IncrementIfRunning() {
+							afterStop++
+						}
+					}
+					results <- afterStop
+
+					return
+				default:
+					c.IncrementIfRunning()
+				}
+			}
+		}()
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	c.Stop()
+	countAtStop := c.Count()
+	close(stopped)
+	wg.Wait()
+	close(results)
+
+	for afterStop := range results {
+		testza.AssertEqual(t, uint64(0), afterStop)
+	}
+
+	testza.AssertGreater(t, countAtStop, uint64(0))
+	testza.AssertEqual(t, countAtStop, c.Count())
+}
+
+func TestCounter_FirstIncrement(t *testing.T) {
+	var calls int32
+
+	c := NewCounter().FirstIncrement(func() { atomic.AddInt32(&calls, 1) }).Start()
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			<-start
+			c.Increment()
+		}()
+	}
+
+	close(start)
+	wg.Wait()
+
+	testza.AssertEqual(t, int32(1), atomic.LoadInt32(&calls))
+
+	c.Reset()
+	c.Increment()
+	testza.AssertEqual(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestCounter_IncrementAt(t *testing.T) {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	offsets := []time.Duration{0, 300, 100, 200, 1200, 700}
+
+	c := NewCounter().WithAdvancedStats()
+	for _, offset := range offsets {
+		c.IncrementAt(base.Add(offset * time.Millisecond))
+	}
+
+	testza.AssertEqual(t, uint64(len(offsets)), c.Count())
+	testza.AssertTrue(t, c.FirstIncrementTime().Equal(base))
+	testza.AssertTrue(t, c.LastIncrementTime().Equal(base.Add(1200*time.Millisecond)))
+	testza.AssertEqual(t, 10.0, c.CalculateMaximumRate(time.Second))
+	testza.AssertEqual(t, 2.0, c.CalculateMinimumRate(time.Second))
+
+	for i := 1; i < len(c.triggers); i++ {
+		testza.AssertFalse(t, c.triggers[i].Before(c.triggers[i-1]))
+	}
+}
+
+func TestCounter_CopyFrom(t *testing.T) {
+	clock := newFakeClock()
+	src := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+	for i := 0; i < 5; i++ {
+		clock.Advance(100 * time.Millisecond)
+		src.Increment()
+	}
+	src.Stop()
+
+	c := NewCounter().WithClock(clock).Start()
+	c.IncrementBy(100)
+	c.CopyFrom(src)
+
+	testza.AssertEqual(t, src.Count(), c.Count())
+	testza.AssertEqual(t, src.triggers, c.triggers)
+	testza.AssertEqual(t, src.startedAt, c.startedAt)
+	testza.AssertEqual(t, src.stoppedAt, c.stoppedAt)
+	testza.AssertEqual(t, StateStopped, c.state)
+	testza.AssertEqual(t, src.CalculateAverageRate(time.Second), c.CalculateAverageRate(time.Second))
+	testza.AssertEqual(t, src.CalculateMaximumRate(time.Second), c.CalculateMaximumRate(time.Second))
+
+	t.Run("Independent", func(t *testing.T) {
+		c.Increment()
+		src.Decrement()
+
+		testza.AssertEqual(t, uint64(6), c.Count())
+		testza.AssertEqual(t, uint64(4), src.Count())
+		testza.AssertLen(t, c.triggers, 6)
+		testza.AssertLen(t, src.triggers, 5)
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 100; i++ {
+			wg.Add(2)
+
+			go func() {
+				defer wg.Done()
+				c.CopyFrom(src)
+			}()
+
+			go func() {
+				defer wg.Done()
+				src.CopyFrom(c)
+			}()
+		}
+
+		wg.Wait()
+		testza.AssertEqual(t, c.Count(), src.Count())
+	})
+}
+
+func TestCounter_State(t *testing.T) {
+	newStopped := func(clock *fakeClock) *Counter {
+		c := NewCounter().WithClock(clock).Start()
+		clock.Advance(time.Second)
+		c.IncrementBy(10)
+		c.Stop()
+
+		return c
+	}
+
+	t.Run("New", func(t *testing.T) {
+		c := NewCounter()
+		testza.AssertEqual(t, StateNeverStarted, c.State())
+		testza.AssertEqual(t, 0.0, c.CalculateAverageRate(time.Second))
+	})
+
+	t.Run("Start Start", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewCounter().WithClock(clock).Start()
+		startedAt := c.startedAt
+
+		clock.Advance(time.Second)
+		c.Start()
+		testza.AssertEqual(t, StateRunning, c.State())
+		testza.AssertEqual(t, startedAt, c.startedAt)
+	})
+
+	t.Run("Stop before Start", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewCounter().WithClock(clock)
+		c.Stop()
+		testza.AssertEqual(t, StateNeverStarted, c.State())
+		testza.AssertTrue(t, c.stoppedAt.IsZero())
+
+		c.Start()
+		clock.Advance(2 * time.Second)
+		c.IncrementBy(10)
+		testza.AssertEqual(t, StateRunning, c.State())
+		testza.AssertEqual(t, 5.0, c.CalculateAverageRate(time.Second))
+	})
+
+	t.Run("Stop Stop", func(t *testing.T) {
+		clock := newFakeClock()
+		c := newStopped(clock)
+		stoppedAt := c.stoppedAt
+
+		clock.Advance(time.Second)
+		c.Stop()
+		testza.AssertEqual(t, StateStopped, c.State())
+		testza.AssertEqual(t, stoppedAt, c.stoppedAt)
+		testza.AssertEqual(t, 10.0, c.CalculateAverageRate(time.Second))
+	})
+
+	t.Run("Stop Start", func(t *testing.T) {
+		clock := newFakeClock()
+		c := newStopped(clock)
+
+		// restarting at the same instant must not leave the measured time span frozen
+		c.Start()
+		testza.AssertEqual(t, StateRunning, c.State())
+
+		clock.Advance(5 * time.Second)
+		testza.AssertEqual(t, 2.0, c.CalculateAverageRate(time.Second))
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		clock := newFakeClock()
+		counters := map[string]*Counter{
+			"never started": NewCounter().WithClock(clock),
+			"running":       NewCounter().WithClock(clock).Start(),
+			"stopped":       newStopped(clock),
+		}
+
+		for name, c := range counters {
+			c.Reset()
+			testza.AssertEqual(t, StateNeverStarted, c.State(), name)
+			testza.AssertEqual(t, 0.0, c.CalculateAverageRate(time.Second), name)
+
+			c.Start()
+			clock.Advance(time.Second)
+			c.Increment()
+			testza.AssertEqual(t, 1.0, c.CalculateAverageRate(time.Second), name)
+			c.Stop()
+		}
+	})
+
+	testza.AssertEqual(t, "never started", StateNeverStarted.String())
+	testza.AssertEqual(t, "running", StateRunning.String())
+	testza.AssertEqual(t, "stopped", StateStopped.String())
+}
+
+func TestCounter_StartFresh(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+
+	for i := 0; i < 10; i++ {
+		clock.Advance(time.Second)
+		c.Increment()
+	}
+
+	// Start on a running counter doesn't restart the time span
+	c.Start()
+	testza.AssertEqual(t, 1.0, c.CalculateAverageRate(time.Second))
+
+	c.StartFresh()
+	testza.AssertEqual(t, uint64(10), c.Count())
+	testza.AssertEqual(t, 0.0, c.CalculateAverageRate(time.Second))
+	testza.AssertEqual(t, 0, c.SampleCount())
+
+	for i := 0; i < 10; i++ {
+		clock.Advance(100 * time.Millisecond)
+		c.Increment()
+	}
+
+	testza.AssertEqual(t, uint64(20), c.Count())
+	testza.AssertInRange(t, c.CalculateAverageRate(time.Second), 10-1e-9, 10+1e-9)
+	testza.AssertInRange(t, c.CalculateMinimumRate(time.Second), 10-1e-9, 10+1e-9)
+
+	// a stopped counter is started
+	c.Stop()
+	c.StartFresh()
+	testza.AssertEqual(t, StateRunning, c.State())
+	clock.Advance(time.Second)
+	c.Increment()
+	testza.AssertEqual(t, 1.0, c.CalculateAverageRate(time.Second))
+}
+
+func TestCounter_ResetKeepRunning(t *testing.T) {
+	c := NewCounter().WithAdvancedStats().Start()
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					c.Increment()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		c.ResetKeepRunning()
+	}
+
+	close(stop)
+	wg.Wait()
+
+	c.ResetKeepRunning()
+	testza.AssertEqual(t, uint64(0), c.Count())
+	testza.AssertEqual(t, StateRunning, c.state)
+
+	c.Increment()
+	c.Increment()
+	testza.AssertEqual(t, uint64(2), c.Count())
+	testza.AssertLen(t, c.triggers, 2)
+	testza.AssertGreater(t, c.CalculateAverageRate(time.Second), 0.0)
+
+	t.Run("Not running", func(t *testing.T) {
+		c.Stop()
+		c.ResetKeepRunning()
+		testza.AssertEqual(t, uint64(0), c.Count())
+		testza.AssertEqual(t, StateNeverStarted, c.state)
+	})
+}
+
+func TestCounter_WithOnReset(t *testing.T) {
+	var resets int
+
+	var c *Counter
+	c = NewCounter().WithOnReset(func() {
+		resets++
+		testza.AssertEqual(t, uint64(0), c.Count())
+	}).Start()
+
+	c.Increment()
+	c.Reset()
+	testza.AssertEqual(t, 1, resets)
+
+	c.Start()
+	c.Increment()
+	c.ResetKeepRunning()
+	testza.AssertEqual(t, 2, resets)
+
+	c.Stop()
+	c.ResetKeepRunning()
+	testza.AssertEqual(t, 3, resets)
+
+	c.Reset()
+	testza.AssertEqual(t, 4, resets)
+}
+
 // basicCounter is a basic implementation of a counter.
 // It's used to compare the performance to our version.
 type basicCounter struct {
@@ -69,6 +1289,55 @@ func (c *basicCounter) Count() uint64 {
 	return c.count
 }
 
+// dispatchCounter increments a Counter via an increment function selected once, when it is created, instead of checking
+// c.enableStats and the other options on every increment like Counter.increment does.
+type dispatchCounter struct {
+	counter   *Counter
+	increment func(c *Counter)
+}
+
+func newDispatchCounter(c *Counter) *dispatchCounter {
+	d := &dispatchCounter{counter: c, increment: incrementPlain}
+	if c.enableStats {
+		d.increment = func(c *Counter) { c.increment(1, time.Time{})() }
+	}
+
+	return d
+}
+
+func (d *dispatchCounter) Increment() {
+	d.counter.mutex.Lock()
+	defer d.counter.mutex.Unlock()
+	d.increment(d.counter)
+}
+
+// incrementPlain is the increment path of a counter without advanced stats and without any other option.
+func incrementPlain(c *Counter) {
+	c.count = addSaturating(c.count, 1)
+	c.totalIncremented = addSaturating(c.totalIncremented, 1)
+	c.pendingRate = addSaturating(c.pendingRate, 1)
+}
+
+// BenchmarkStatsDispatch compares the branch on c.enableStats in the increment path of Counter
+// with a path without advanced stats that is selected once via a function pointer.
+func BenchmarkStatsDispatch(b *testing.B) {
+	b.Run("Branch", func(b *testing.B) {
+		counter := NewCounter().Start()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			counter.Increment()
+		}
+	})
+
+	b.Run("FunctionPointer", func(b *testing.B) {
+		counter := newDispatchCounter(NewCounter().Start())
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			counter.Increment()
+		}
+	})
+}
+
 func BenchmarkBasicCounterImplementation(b *testing.B) {
 	counter := basicCounter{}
 	b.ResetTimer()
@@ -92,3 +1361,86 @@ func BenchmarkIncrementWithAdvancedStats(b *testing.B) {
 		counter.Increment()
 	}
 }
+
+func BenchmarkIncrementWithAdvancedStatsSampling(b *testing.B) {
+	counter := NewCounter().WithAdvancedStats().WithSampling(100).Start()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		counter.Increment()
+	}
+}
+
+func BenchmarkBasicCounterImplementationParallel(b *testing.B) {
+	counter := basicCounter{}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			counter.Increment()
+		}
+	})
+}
+
+func BenchmarkIncrementParallel(b *testing.B) {
+	counter := NewCounter().Start()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			counter.Increment()
+		}
+	})
+}
+
+func BenchmarkIncrementWithAdvancedStatsParallel(b *testing.B) {
+	counter := NewCounter().WithAdvancedStats().Start()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			counter.Increment()
+		}
+	})
+}
+
+func BenchmarkCountParallel(b *testing.B) {
+	counter := NewCounter().Start()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			counter.Count()
+		}
+	})
+}
+
+// BenchmarkIncrementDuringPercentileRate measures the latency of increments, while another goroutine keeps calculating
+// a percentile rate over many recorded increments. It should be close to BenchmarkIncrementWithAdvancedStats.
+func BenchmarkIncrementDuringPercentileRate(b *testing.B) {
+	c := NewCounter().WithAdvancedStats().WithMaxSamples(100_000).Start()
+	for i := 0; i < 100_000; i++ {
+		c.Increment()
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				c.CalculatePercentileRate(99, time.Second)
+			}
+		}
+	}()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		c.Increment()
+	}
+
+	b.StopTimer()
+	close(done)
+	<-stopped
+}
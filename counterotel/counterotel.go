@@ -0,0 +1,52 @@
+/*
+Package counterotel exports counters from atomicgo.dev/counter as OpenTelemetry instruments.
+*/
+package counterotel
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+
+	"atomicgo.dev/counter"
+)
+
+// Register registers c with the provided meter.
+// The count is exported as an Int64ObservableCounter called name, and the average rate
+// (in `count / interval`) as a Float64ObservableGauge called name + ".rate".
+// Call Unregister on the returned registration to stop exporting the counter.
+func Register(meter metric.Meter, name string, c *counter.Counter, interval time.Duration) (metric.Registration, error) {
+	count, err := meter.Int64ObservableCounter(name, metric.WithDescription("Current count of the counter."))
+	if err != nil {
+		return nil, fmt.Errorf("creating count instrument: %w", err)
+	}
+
+	rate, err := meter.Float64ObservableGauge(name+".rate", metric.WithDescription("Average rate of the counter in count / "+interval.String()+"."))
+	if err != nil {
+		return nil, fmt.Errorf("creating rate instrument: %w", err)
+	}
+
+	reg, err := meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveInt64(count, toInt64(c.Count()))
+		o.ObserveFloat64(rate, c.CalculateAverageRate(interval))
+
+		return nil
+	}, count, rate)
+	if err != nil {
+		return nil, fmt.Errorf("registering callback: %w", err)
+	}
+
+	return reg, nil
+}
+
+// toInt64 converts a count to an int64, saturating at math.MaxInt64.
+func toInt64(n uint64) int64 {
+	if n > math.MaxInt64 {
+		return math.MaxInt64
+	}
+
+	return int64(n)
+}
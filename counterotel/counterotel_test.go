@@ -0,0 +1,46 @@
+package counterotel_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"atomicgo.dev/counter"
+	"atomicgo.dev/counter/counterotel"
+)
+
+func TestRegister(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	meter := provider.Meter("test")
+
+	c := counter.NewCounter().Start()
+	for i := 0; i < 42; i++ {
+		c.Increment()
+	}
+	c.Stop()
+
+	_, err := counterotel.Register(meter, "requests", c, time.Second)
+	testza.AssertNoError(t, err)
+
+	var rm metricdata.ResourceMetrics
+	testza.AssertNoError(t, reader.Collect(context.Background(), &rm))
+	testza.AssertLen(t, rm.ScopeMetrics, 1)
+
+	metrics := map[string]metricdata.Aggregation{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m.Data
+	}
+
+	sum, ok := metrics["requests"].(metricdata.Sum[int64])
+	testza.AssertTrue(t, ok)
+	testza.AssertEqual(t, int64(42), sum.DataPoints[0].Value)
+
+	gauge, ok := metrics["requests.rate"].(metricdata.Gauge[float64])
+	testza.AssertTrue(t, ok)
+	testza.AssertEqual(t, c.CalculateAverageRate(time.Second), gauge.DataPoints[0].Value)
+}
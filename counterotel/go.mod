@@ -0,0 +1,39 @@
+module atomicgo.dev/counter/counterotel
+
+go 1.21
+
+require (
+	atomicgo.dev/counter v0.1.0
+	github.com/MarvinJWendt/testza v0.5.1
+	go.opentelemetry.io/otel/metric v1.29.0
+	go.opentelemetry.io/otel/sdk/metric v1.29.0
+)
+
+require (
+	atomicgo.dev/assert v0.0.2 // indirect
+	atomicgo.dev/cursor v0.1.1 // indirect
+	atomicgo.dev/keyboard v0.2.8 // indirect
+	github.com/containerd/console v1.0.3 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/gookit/color v1.5.2 // indirect
+	github.com/klauspost/cpuid/v2 v2.2.0 // indirect
+	github.com/lithammer/fuzzysearch v1.1.5 // indirect
+	github.com/mattn/go-runewidth v0.0.13 // indirect
+	github.com/pterm/pterm v0.12.49 // indirect
+	github.com/rivo/uniseg v0.2.0 // indirect
+	github.com/sergi/go-diff v1.2.0 // indirect
+	github.com/xo/terminfo v0.0.0-20210125001918-ca9a967f8778 // indirect
+	go.opentelemetry.io/otel v1.29.0 // indirect
+	go.opentelemetry.io/otel/sdk v1.29.0 // indirect
+	go.opentelemetry.io/otel/trace v1.29.0 // indirect
+	golang.org/x/sys v0.24.0 // indirect
+	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211 // indirect
+	golang.org/x/text v0.3.7 // indirect
+)
+
+// Consumers get the release of atomicgo.dev/counter required above; within the repository, the module is built and tested
+// against the working tree.
+replace atomicgo.dev/counter => ../
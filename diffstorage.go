@@ -0,0 +1,160 @@
+package counter
+
+import (
+	"slices"
+	"time"
+)
+
+// WithDiffStorage makes the advanced stats store the durations between consecutive increments,
+// instead of the timestamps of the increments.
+// A duration takes a third of the memory of a timestamp, and the rate statistics are calculated from the durations directly.
+// Methods that need the timestamps, like WindowedMaxRate or WriteCSV, reconstruct them from the durations, which makes them slower.
+// Increments recorded out of order via IncrementAt are also slower to record.
+// Already recorded increments are converted.
+func (c *Counter) WithDiffStorage() *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.diffStorage {
+		return c
+	}
+
+	triggers := c.samples()
+	c.diffStorage = true
+	c.triggers = nil
+	c.setSamples(triggers)
+	c.setMaxSamples(c.maxSamples)
+
+	return c
+}
+
+// recordGap records an increment at t in diff storage.
+// If the number of recorded increments is bounded, the gaps are kept in a ring buffer of that size, like the timestamps,
+// and the oldest increment is dropped.
+// It must be called with c.mutex held.
+func (c *Counter) recordGap(t time.Time) {
+	n := len(c.gaps)
+	if n > 0 && t.Before(c.gapsTo) {
+		c.insert(t)
+		return
+	}
+
+	if n == 0 {
+		c.gapsFrom = t
+		c.gapsTo = t
+		c.gaps = append(c.gaps, 0)
+
+		return
+	}
+
+	gap := t.Sub(c.gapsTo)
+	c.gapsTo = t
+
+	if c.maxSamples <= 0 {
+		c.gaps = append(c.gaps, gap)
+		return
+	}
+
+	if n < c.maxSamples {
+		if n == cap(c.gaps) {
+			gaps := make([]time.Duration, n, min(c.maxSamples, max(2*n, 8)))
+			copy(gaps, c.gaps)
+			c.gaps = gaps
+		}
+
+		c.gaps = append(c.gaps, gap)
+
+		return
+	}
+
+	if n == 1 {
+		c.gapsFrom = t
+		return
+	}
+
+	// the ring buffer is full: the second oldest increment becomes the first, and the slot of the oldest one takes the new one
+	next := (c.ringHead + 1) % n
+	c.gapsFrom = c.gapsFrom.Add(c.gaps[next])
+	c.gaps[next] = 0
+	c.gaps[c.ringHead] = gap
+	c.ringHead = next
+}
+
+// orderedGaps returns the gaps of diff storage in chronological order, starting with the 0 of the first increment.
+// If the ring buffer of a bounded counter wrapped around, it is rotated in place.
+// It must be called with c.mutex held.
+func (c *Counter) orderedGaps() []time.Duration {
+	if c.ringHead != 0 {
+		slices.Reverse(c.gaps[:c.ringHead])
+		slices.Reverse(c.gaps[c.ringHead:])
+		slices.Reverse(c.gaps)
+		c.ringHead = 0
+	}
+
+	return c.gaps
+}
+
+// decodeGaps returns the timestamps of the increments recorded in diff storage, in chronological order.
+// It must be called with c.mutex held.
+func (c *Counter) decodeGaps() []time.Time {
+	gaps := c.orderedGaps()
+	if len(gaps) == 0 {
+		return nil
+	}
+
+	triggers := make([]time.Time, len(gaps))
+
+	t := c.gapsFrom
+	for i, gap := range gaps {
+		t = t.Add(gap)
+		triggers[i] = t
+	}
+
+	return triggers
+}
+
+// setSamples replaces the recorded increments with triggers, which must be in chronological order.
+// Without diff storage, triggers is used as is.
+// It must be called with c.mutex held.
+func (c *Counter) setSamples(triggers []time.Time) {
+	c.ringHead = 0
+
+	if !c.diffStorage {
+		c.triggers = triggers
+		return
+	}
+
+	if len(triggers) == 0 {
+		c.gaps = nil
+		return
+	}
+
+	c.gaps = make([]time.Duration, len(triggers))
+	for i := 1; i < len(triggers); i++ {
+		c.gaps[i] = triggers[i].Sub(triggers[i-1])
+	}
+
+	c.gapsFrom = triggers[0]
+	c.gapsTo = triggers[len(triggers)-1]
+}
+
+// recorded returns the number of recorded increments.
+// It must be called with c.mutex held.
+func (c *Counter) recorded() int {
+	if c.diffStorage {
+		return len(c.gaps)
+	}
+
+	return len(c.triggers)
+}
+
+// compactGaps releases the memory of diff storage that is not used anymore.
+// It must be called with c.mutex held.
+func (c *Counter) compactGaps() {
+	switch {
+	case len(c.gaps) == 0:
+		c.gaps = nil
+	case len(c.gaps) < cap(c.gaps):
+		c.gaps = slices.Clone(c.gaps)
+	}
+}
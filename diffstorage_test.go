@@ -0,0 +1,94 @@
+package counter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestCounter_WithDiffStorage(t *testing.T) {
+	for _, maxSamples := range []int{0, 1, 5} {
+		clock := newFakeClock()
+		timestamps := NewCounter().WithClock(clock).WithAdvancedStats().WithMaxSamples(maxSamples).Start()
+		diffs := NewCounter().WithClock(clock).WithAdvancedStats().WithMaxSamples(maxSamples).WithDiffStorage().Start()
+
+		for i, d := range []time.Duration{10, 30, 5, 50, 20, 1, 40, 15, 25} {
+			clock.Advance(d * time.Millisecond)
+
+			if i == 6 {
+				// out of order
+				timestamps.IncrementAt(clock.Now().Add(-30 * time.Millisecond))
+				diffs.IncrementAt(clock.Now().Add(-30 * time.Millisecond))
+			}
+
+			timestamps.Increment()
+			diffs.Increment()
+		}
+
+		testza.AssertEqual(t, timestamps.samples(), diffs.samples(), maxSamples)
+		testza.AssertEqual(t, timestamps.CalculateMaximumRate(time.Second), diffs.CalculateMaximumRate(time.Second), maxSamples)
+		testza.AssertEqual(t, timestamps.CalculateMinimumRate(time.Second), diffs.CalculateMinimumRate(time.Second), maxSamples)
+		testza.AssertEqual(t, timestamps.CalculateJitter(), diffs.CalculateJitter(), maxSamples)
+		testza.AssertEqual(t, timestamps.CalculateCoefficientOfVariation(), diffs.CalculateCoefficientOfVariation(), maxSamples)
+		testza.AssertEqual(t, timestamps.WindowedMaxRate(100*time.Millisecond, time.Second), diffs.WindowedMaxRate(100*time.Millisecond, time.Second), maxSamples)
+		testza.AssertEqual(t, timestamps.FirstIncrementTime(), diffs.FirstIncrementTime(), maxSamples)
+		testza.AssertEqual(t, timestamps.LastIncrementTime(), diffs.LastIncrementTime(), maxSamples)
+	}
+
+	t.Run("Conversion", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+
+		for i := 0; i < 10; i++ {
+			clock.Advance(time.Duration(i) * time.Millisecond)
+			c.Increment()
+		}
+
+		triggers := c.samples()
+		c.WithDiffStorage()
+		testza.AssertNil(t, c.triggers)
+		testza.AssertLen(t, c.gaps, 10)
+		testza.AssertEqual(t, triggers, c.samples())
+
+		c.Reset()
+		testza.AssertEqual(t, 0, c.recorded())
+		testza.AssertEqual(t, 0.0, c.CalculateMaximumRate(time.Second))
+	})
+
+	t.Run("Memory", func(t *testing.T) {
+		timestamps := NewCounter().WithAdvancedStats().Start()
+		diffs := NewCounter().WithAdvancedStats().WithDiffStorage().Start()
+
+		for i := 0; i < 10_000; i++ {
+			timestamps.Increment()
+			diffs.Increment()
+		}
+
+		timestamps.Compact()
+		diffs.Compact()
+		testza.AssertLess(t, diffs.MemoryUsage(), timestamps.MemoryUsage()/2)
+	})
+}
+
+func BenchmarkIncrementWithDiffStorage(b *testing.B) {
+	for _, bench := range []struct {
+		name       string
+		newCounter func() *Counter
+	}{
+		{"Timestamps", func() *Counter { return NewCounter().WithAdvancedStats().Start() }},
+		{"Diffs", func() *Counter { return NewCounter().WithAdvancedStats().WithDiffStorage().Start() }},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			counter := bench.newCounter()
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				counter.Increment()
+			}
+
+			b.ReportMetric(float64(counter.MemoryUsage())/float64(b.N), "mem-B/op")
+		})
+	}
+}
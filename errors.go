@@ -0,0 +1,22 @@
+package counter
+
+import "errors"
+
+// ErrStatsDisabled is returned by operations that need advanced statistics, when they are not enabled via WithAdvancedStats.
+var ErrStatsDisabled = errors.New("advanced stats are not enabled")
+
+// ErrCorruptState is returned by Validate, and wrapped by the errors of GobDecode, when the state of a counter is inconsistent.
+var ErrCorruptState = errors.New("corrupt counter state")
+
+// ErrNotStarted is returned by operations that need a measured time span, when the counter was never started.
+var ErrNotStarted = errors.New("counter was not started")
+
+// ErrOverflow is returned by checked operations that would overflow the count, instead of saturating it.
+var ErrOverflow = errors.New("count overflow")
+
+// ErrSamplingMismatch is returned by MergeStats, when the recorded increments of a counter were sampled differently via WithSampling.
+var ErrSamplingMismatch = errors.New("sampling of the counters differs")
+
+// ErrLightStats is returned by MergeStats, when the advanced stats of a counter are light stats via WithLightStats,
+// which only keep the extremes of their own increments and can't be combined with the increments of other counters.
+var ErrLightStats = errors.New("light stats can't be merged")
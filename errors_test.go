@@ -0,0 +1,50 @@
+package counter
+
+import (
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestErrors(t *testing.T) {
+	t.Run("NotStarted", func(t *testing.T) {
+		_, err := NewCounter().CalculateAverageRateErr(time.Second)
+		testza.AssertErrorIs(t, err, ErrNotStarted)
+
+		_, err = NewCounter().Start().CalculateAverageRateErr(time.Second)
+		testza.AssertNoError(t, err)
+	})
+
+	t.Run("StatsDisabled", func(t *testing.T) {
+		c := NewCounter().Start()
+
+		_, err := c.CalculateMaximumRateErr(time.Second)
+		testza.AssertErrorIs(t, err, ErrStatsDisabled)
+
+		_, err = c.CalculateMinimumRateErr(time.Second)
+		testza.AssertErrorIs(t, err, ErrStatsDisabled)
+		testza.AssertErrorIs(t, c.WriteCSV(io.Discard), ErrStatsDisabled)
+	})
+
+	t.Run("CorruptState", func(t *testing.T) {
+		c := NewCounter().Start()
+		c.Stop()
+		c.stoppedAt = c.startedAt.Add(-time.Second)
+		testza.AssertErrorIs(t, c.Validate(), ErrCorruptState)
+
+		data, err := c.GobEncode()
+		testza.AssertNoError(t, err)
+		testza.AssertErrorIs(t, NewCounter().GobDecode(data), ErrCorruptState)
+	})
+
+	t.Run("Overflow", func(t *testing.T) {
+		c := NewCounter()
+		testza.AssertNoError(t, c.IncrementByErr(math.MaxUint64-1))
+		testza.AssertErrorIs(t, c.IncrementByErr(2), ErrOverflow)
+		testza.AssertEqual(t, uint64(math.MaxUint64-1), c.Count())
+		testza.AssertNoError(t, c.IncrementByErr(1))
+	})
+}
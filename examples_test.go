@@ -2,11 +2,32 @@ package counter_test
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"atomicgo.dev/counter"
 )
 
+// fakeClock is a counter.Clock that only advances when told to.
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.now
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.now = f.now.Add(d)
+}
+
 func ExampleCounter_Increment() {
 	c := counter.NewCounter().Start()
 	for i := 0; i < 10; i++ {
@@ -19,39 +40,45 @@ func ExampleCounter_Increment() {
 }
 
 func ExampleCounter_CalculateAverageRate() {
-	c := counter.NewCounter().Start()
+	clock := &fakeClock{now: time.Now()}
+	c := counter.NewCounter().WithClock(clock).Start()
 	for i := 0; i < 10; i++ {
-		time.Sleep(100 * time.Millisecond)
+		clock.Advance(100 * time.Millisecond)
 		c.Increment()
 	}
 	c.Stop()
 
+	// We incremented 10 times in 1 second
 	fmt.Println(c.CalculateAverageRate(time.Second))
-	// Output should be around 10, as we incremented 10 times in 1 second
+	// Output: 10
 }
 
 func ExampleCounter_CalculateMinimumRate() {
-	c := counter.NewCounter().WithAdvancedStats().Start()
+	clock := &fakeClock{now: time.Now()}
+	c := counter.NewCounter().WithClock(clock).WithAdvancedStats().Start()
 	for i := 0; i < 10; i++ {
-		time.Sleep(100 * time.Millisecond)
+		clock.Advance(time.Duration(i+1) * 10 * time.Millisecond)
 		c.Increment()
 	}
 	c.Stop()
 
+	// The longest gap between two increments was 100ms
 	fmt.Println(c.CalculateMinimumRate(time.Second))
-	// Output should be around 10, as we incremented 10 times in 1 second
+	// Output: 10
 }
 
 func ExampleCounter_CalculateMaximumRate() {
-	c := counter.NewCounter().WithAdvancedStats().Start()
+	clock := &fakeClock{now: time.Now()}
+	c := counter.NewCounter().WithClock(clock).WithAdvancedStats().Start()
 	for i := 0; i < 10; i++ {
-		time.Sleep(100 * time.Millisecond)
+		clock.Advance(time.Duration(10-i) * 10 * time.Millisecond)
 		c.Increment()
 	}
 	c.Stop()
 
+	// The shortest gap between two increments was 10ms
 	fmt.Println(c.CalculateMaximumRate(time.Second))
-	// Output should be around 10, as we incremented 10 times in 1 second
+	// Output: 100
 }
 
 func ExampleCounter_Reset() {
@@ -0,0 +1,93 @@
+package counter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// WriteCSV writes the timestamps of all recorded increments to w as CSV.
+// Each row contains the cumulative index of the increment (starting at 1) and its timestamp in RFC3339Nano format.
+// The first row is a header.
+// It returns ErrStatsDisabled if advanced stats are not enabled via WithAdvancedStats.
+func (c *Counter) WriteCSV(w io.Writer) error {
+	c.mutex.Lock()
+
+	if !c.enableStats {
+		c.mutex.Unlock()
+		return ErrStatsDisabled
+	}
+
+	triggers := slices.Clone(c.samples())
+	c.mutex.Unlock()
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"index", "timestamp"}); err != nil {
+		return fmt.Errorf("writing csv header: %w", err)
+	}
+
+	for i, t := range triggers {
+		if err := cw.Write([]string{strconv.Itoa(i + 1), t.Format(time.RFC3339Nano)}); err != nil {
+			return fmt.Errorf("writing csv row: %w", err)
+		}
+	}
+
+	cw.Flush()
+
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("writing csv: %w", err)
+	}
+
+	return nil
+}
+
+// Bucket is a time window of a downsampled series.
+type Bucket struct {
+	// Start is the beginning of the window.
+	Start time.Time
+	// Count is the number of increments within the window.
+	Count uint64
+}
+
+// DownsampledSeries divides the time span of the counter into the given number of equal windows,
+// and returns the number of increments within each of them.
+// The time span starts when the counter was started and ends when it was stopped, or now if it's still running.
+// It returns nil if buckets is not positive.
+// Needs to be enabled via WithAdvancedStats.
+func (c *Counter) DownsampledSeries(buckets int) []Bucket {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.enableStats || buckets <= 0 {
+		return nil
+	}
+
+	start := c.startedAt
+	span := c.until().Sub(start)
+	width := span / time.Duration(buckets)
+
+	series := make([]Bucket, buckets)
+	for i := range series {
+		series[i].Start = start.Add(width * time.Duration(i))
+	}
+
+	for _, t := range c.samples() {
+		i := 0
+		if span > 0 {
+			i = int(float64(t.Sub(start)) / float64(span) * float64(buckets))
+		}
+
+		if i < 0 {
+			i = 0
+		} else if i >= buckets {
+			i = buckets - 1
+		}
+
+		series[i].Count++
+	}
+
+	return series
+}
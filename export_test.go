@@ -0,0 +1,73 @@
+package counter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestCounter_WriteCSV(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		c := NewCounter().WithAdvancedStats().Start()
+		for i := 0; i < 5; i++ {
+			c.Increment()
+		}
+		c.Stop()
+
+		var buf bytes.Buffer
+		testza.AssertNoError(t, c.WriteCSV(&buf))
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		testza.AssertNoError(t, err)
+		testza.AssertLen(t, records, 6)
+		testza.AssertEqual(t, []string{"index", "timestamp"}, records[0])
+
+		for i, record := range records[1:] {
+			testza.AssertEqual(t, strconv.Itoa(i+1), record[0])
+
+			ts, err := time.Parse(time.RFC3339Nano, record[1])
+			testza.AssertNoError(t, err)
+			testza.AssertTrue(t, ts.Equal(c.triggers[i]))
+		}
+	})
+
+	t.Run("Advanced stats disabled", func(t *testing.T) {
+		var buf bytes.Buffer
+		testza.AssertErrorIs(t, NewCounter().WriteCSV(&buf), ErrStatsDisabled)
+		testza.AssertEqual(t, 0, buf.Len())
+	})
+}
+
+func TestCounter_DownsampledSeries(t *testing.T) {
+	c := NewCounter().WithAdvancedStats().Start()
+
+	for i := 0; i < 100; i++ {
+		c.Increment()
+	}
+	time.Sleep(20 * time.Millisecond)
+	for i := 0; i < 10; i++ {
+		c.Increment()
+		time.Sleep(time.Millisecond)
+	}
+	c.Stop()
+
+	series := c.DownsampledSeries(4)
+	testza.AssertLen(t, series, 4)
+
+	var sum uint64
+	for _, b := range series {
+		sum += b.Count
+	}
+
+	testza.AssertEqual(t, uint64(110), sum)
+	testza.AssertGreaterOrEqual(t, series[0].Count, uint64(100))
+	testza.AssertTrue(t, series[0].Start.Equal(c.startedAt))
+	testza.AssertTrue(t, series[1].Start.After(series[0].Start))
+
+	testza.AssertNil(t, c.DownsampledSeries(0))
+	testza.AssertNil(t, NewCounter().Start().DownsampledSeries(4))
+}
@@ -0,0 +1,51 @@
+package counter
+
+import "sync/atomic"
+
+// FixedGroup is a fixed set of related counters, e.g. one per status code, which are updated and read without locking.
+// It is lighter than a set of Counters, but doesn't collect any statistics.
+type FixedGroup struct {
+	cells []cell
+}
+
+// cell is a single counter of a FixedGroup, padded to a cache line,
+// so that writers of neighbouring counters don't invalidate each other's cache lines on every increment.
+type cell struct {
+	atomic.Uint64
+	_ [56]byte
+}
+
+// NewFixedGroup returns a new FixedGroup with n counters.
+func NewFixedGroup(n int) *FixedGroup {
+	return &FixedGroup{cells: make([]cell, n)}
+}
+
+// IncrementIndex increments the counter at index i by 1.
+// It panics if i is out of range.
+func (g *FixedGroup) IncrementIndex(i int) {
+	g.cells[i].Add(1)
+}
+
+// Count returns the count of the counter at index i.
+// It panics if i is out of range.
+func (g *FixedGroup) Count(i int) uint64 {
+	return g.cells[i].Load()
+}
+
+// Total returns the sum of all counters.
+// While the counters are incremented concurrently, the sum may include only some of the concurrent increments.
+// Total isn't cached: every call loads each counter once, so it costs O(Len) atomic loads and is never staler than
+// the moment it is called, unlike Count on a single Counter, which is a single load.
+func (g *FixedGroup) Total() uint64 {
+	var total uint64
+	for i := range g.cells {
+		total += g.cells[i].Load()
+	}
+
+	return total
+}
+
+// Len returns the number of counters in the group.
+func (g *FixedGroup) Len() int {
+	return len(g.cells)
+}
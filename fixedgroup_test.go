@@ -0,0 +1,120 @@
+package counter_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+
+	"atomicgo.dev/counter"
+)
+
+func TestFixedGroup(t *testing.T) {
+	g := counter.NewFixedGroup(3)
+	testza.AssertEqual(t, 3, g.Len())
+
+	var wg sync.WaitGroup
+	for i := 0; i < g.Len(); i++ {
+		for j := 0; j < 10; j++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				for k := 0; k <= i*100; k++ {
+					g.IncrementIndex(i)
+				}
+			}(i)
+		}
+	}
+
+	wg.Wait()
+
+	testza.AssertEqual(t, uint64(10), g.Count(0))
+	testza.AssertEqual(t, uint64(1010), g.Count(1))
+	testza.AssertEqual(t, uint64(2010), g.Count(2))
+	testza.AssertEqual(t, uint64(3030), g.Total())
+	testza.AssertPanics(t, func() { g.IncrementIndex(3) })
+}
+
+func BenchmarkFixedGroupIncrementIndexParallel(b *testing.B) {
+	g := counter.NewFixedGroup(8)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			g.IncrementIndex(i % 8)
+			i++
+		}
+	})
+}
+
+func BenchmarkFixedGroupTotalParallel(b *testing.B) {
+	g := counter.NewFixedGroup(8)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if i%2 == 0 {
+				g.IncrementIndex(i % 8)
+			} else {
+				g.Total()
+			}
+			i++
+		}
+	})
+}
+
+// BenchmarkReadUnderContention compares the cost of reading the count of a Counter and the total of a FixedGroup,
+// while other goroutines keep incrementing them.
+func BenchmarkReadUnderContention(b *testing.B) {
+	writers := func(b *testing.B, increment func(i int)) (stop func()) {
+		done := make(chan struct{})
+
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+
+			go func(w int) {
+				defer wg.Done()
+
+				for {
+					select {
+					case <-done:
+						return
+					default:
+						increment(w)
+					}
+				}
+			}(w)
+		}
+
+		b.ResetTimer()
+
+		return func() {
+			b.StopTimer()
+			close(done)
+			wg.Wait()
+		}
+	}
+
+	b.Run("Counter.Count", func(b *testing.B) {
+		c := counter.NewCounter().Start()
+		stop := writers(b, func(int) { c.Increment() })
+		defer stop()
+
+		for i := 0; i < b.N; i++ {
+			c.Count()
+		}
+	})
+
+	b.Run("FixedGroup.Total", func(b *testing.B) {
+		g := counter.NewFixedGroup(8)
+		stop := writers(b, func(w int) { g.IncrementIndex(w) })
+		defer stop()
+
+		for i := 0; i < b.N; i++ {
+			g.Total()
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package counter
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"testing"
+)
+
+const (
+	fuzzOpIncrementBy = iota
+	fuzzOpDecrementBy
+	fuzzOpSet
+	fuzzOpCount
+)
+
+func FuzzCounter(f *testing.F) {
+	seed := func(ops ...uint64) []byte {
+		data := make([]byte, 0, len(ops)*9/2)
+		for i := 0; i+1 < len(ops); i += 2 {
+			data = append(data, byte(ops[i]))
+			data = binary.LittleEndian.AppendUint64(data, ops[i+1])
+		}
+
+		return data
+	}
+
+	f.Add(seed(fuzzOpIncrementBy, 10, fuzzOpDecrementBy, 3))
+	f.Add(seed(fuzzOpDecrementBy, 1, fuzzOpIncrementBy, 1))
+	f.Add(seed(fuzzOpSet, math.MaxUint64, fuzzOpIncrementBy, 1))
+	f.Add(seed(fuzzOpIncrementBy, math.MaxUint64, fuzzOpIncrementBy, math.MaxUint64, fuzzOpDecrementBy, 1))
+
+	maxCount := new(big.Int).SetUint64(math.MaxUint64)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		c := NewCounter().Start()
+		oracle := new(big.Int)
+
+		for len(data) >= 9 {
+			op, n := data[0]%fuzzOpCount, binary.LittleEndian.Uint64(data[1:9])
+			data = data[9:]
+
+			switch op {
+			case fuzzOpIncrementBy:
+				c.IncrementBy(n)
+				oracle.Add(oracle, new(big.Int).SetUint64(n))
+			case fuzzOpDecrementBy:
+				c.DecrementBy(n)
+				oracle.Sub(oracle, new(big.Int).SetUint64(n))
+			case fuzzOpSet:
+				c.Set(n)
+				oracle.SetUint64(n)
+			}
+
+			if oracle.Sign() < 0 {
+				oracle.SetUint64(0)
+			} else if oracle.Cmp(maxCount) > 0 {
+				oracle.Set(maxCount)
+			}
+
+			if c.Count() != oracle.Uint64() {
+				t.Fatalf("count is %d, expected %s", c.Count(), oracle)
+			}
+		}
+	})
+}
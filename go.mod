@@ -1,6 +1,6 @@
 module atomicgo.dev/counter
 
-go 1.18
+go 1.21
 
 require github.com/MarvinJWendt/testza v0.5.1
 
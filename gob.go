@@ -0,0 +1,157 @@
+package counter
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"time"
+)
+
+// gobVersion is the version of the gob encoding of a Counter.
+const gobVersion = 1
+
+// gobState is the state of a Counter, as it is encoded by GobEncode.
+type gobState struct {
+	Version          int
+	Count            uint64
+	TotalIncremented uint64
+	TotalDecremented uint64
+	RateBase         uint64
+	Weighted         float64
+	State            State
+	StartedAt        time.Time
+	StoppedAt        time.Time
+	PausedAt         time.Time
+	PausedTotal      time.Duration
+	Tags             map[string]uint64
+	Metadata         map[string]string
+	Warmup           uint64
+	WarmedUp         uint64
+
+	EnableStats  bool
+	StrictStats  bool
+	DiffStorage  bool
+	Sampling     uint64
+	MaxSamples   int
+	Triggers     []time.Time
+	SeedMinDiff  time.Duration
+	SeedMaxDiff  time.Duration
+	LightStats   bool
+	LightSamples int
+	LightLast    time.Time
+	LightMinDiff time.Duration
+	LightMaxDiff time.Duration
+	LightHasDiff bool
+}
+
+// GobEncode encodes the state of the counter for encoding/gob, so a *Counter can be sent via net/rpc, for example.
+// It encodes what CopyFrom copies: the count, the per-tag counts, the metadata, the lifecycle state and times, the warm-up,
+// and the advanced stats with their configuration and, if enabled, the recorded increments.
+// Configuration like callbacks, background tasks, the clock or the logger is not encoded.
+func (c *Counter) GobEncode() ([]byte, error) {
+	c.mutex.Lock()
+
+	state := gobState{
+		Version:          gobVersion,
+		Count:            c.count,
+		TotalIncremented: c.totalIncremented,
+		TotalDecremented: c.totalDecremented,
+		RateBase:         c.rateBase,
+		Weighted:         math.Float64frombits(c.weighted.Load()),
+		State:            c.state,
+		StartedAt:        c.startedAt,
+		StoppedAt:        c.stoppedAt,
+		PausedAt:         c.pausedAt,
+		PausedTotal:      c.pausedTotal,
+		Tags:             maps.Clone(c.tags),
+		Metadata:         maps.Clone(c.metadata),
+		Warmup:           c.warmup,
+		WarmedUp:         c.warmedUp,
+		EnableStats:      c.enableStats,
+		StrictStats:      c.strictStats,
+		DiffStorage:      c.diffStorage,
+		Sampling:         c.sampling,
+		MaxSamples:       c.maxSamples,
+		SeedMinDiff:      c.seedMinDiff,
+		SeedMaxDiff:      c.seedMaxDiff,
+		LightStats:       c.lightStats,
+		LightSamples:     c.lightSamples,
+		LightLast:        c.lightLast,
+		LightMinDiff:     c.lightMinDiff,
+		LightMaxDiff:     c.lightMaxDiff,
+		LightHasDiff:     c.lightHasDiff,
+	}
+
+	if c.enableStats {
+		state.Triggers = slices.Clone(c.samples())
+	}
+
+	c.mutex.Unlock()
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(state); err != nil {
+		return nil, fmt.Errorf("encoding counter: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// GobDecode overwrites the state of the counter with the state encoded by GobEncode.
+// Like with CopyFrom, the configuration of the counter that is not encoded, e.g. its clock, is kept,
+// and its background tasks are started or stopped according to the decoded state.
+// It returns an error and leaves the counter unchanged, if data is malformed or the decoded state is inconsistent.
+func (c *Counter) GobDecode(data []byte) error {
+	var state gobState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&state); err != nil {
+		return fmt.Errorf("decoding counter: %w", err)
+	}
+
+	if state.Version != gobVersion {
+		return fmt.Errorf("decoding counter: unsupported version %d", state.Version)
+	}
+
+	src := &Counter{
+		count:            state.Count,
+		totalIncremented: state.TotalIncremented,
+		totalDecremented: state.TotalDecremented,
+		rateBase:         state.RateBase,
+		state:            state.State,
+		startedAt:        state.StartedAt,
+		stoppedAt:        state.StoppedAt,
+		pausedAt:         state.PausedAt,
+		pausedTotal:      state.PausedTotal,
+		tags:             state.Tags,
+		metadata:         state.Metadata,
+		warmup:           state.Warmup,
+		warmedUp:         state.WarmedUp,
+		enableStats:      state.EnableStats,
+		strictStats:      state.StrictStats,
+		sampling:         state.Sampling,
+		maxSamples:       state.MaxSamples,
+		triggers:         state.Triggers,
+		seedMinDiff:      state.SeedMinDiff,
+		seedMaxDiff:      state.SeedMaxDiff,
+		lightStats:       state.LightStats,
+		lightSamples:     state.LightSamples,
+		lightLast:        state.LightLast,
+		lightMinDiff:     state.LightMinDiff,
+		lightMaxDiff:     state.LightMaxDiff,
+		lightHasDiff:     state.LightHasDiff,
+	}
+	src.weighted.Store(math.Float64bits(state.Weighted))
+
+	if err := src.Validate(); err != nil {
+		return fmt.Errorf("decoding counter: %w", err)
+	}
+
+	if state.DiffStorage {
+		src.WithDiffStorage()
+	}
+
+	c.CopyFrom(src)
+
+	return nil
+}
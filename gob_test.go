@@ -0,0 +1,114 @@
+package counter
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestCounter_Gob(t *testing.T) {
+	clock := newFakeClock()
+	original := NewCounter().WithClock(clock).WithAdvancedStats().WithMaxSamples(100).Start()
+
+	for i := 0; i < 10; i++ {
+		clock.Advance(time.Duration(i+1) * 10 * time.Millisecond)
+		original.Increment()
+	}
+
+	original.Decrement()
+	original.AddWeighted(1.5)
+	original.SetMinMaxDiff(time.Millisecond, time.Second)
+	original.Stop()
+
+	var buf bytes.Buffer
+	testza.AssertNoError(t, gob.NewEncoder(&buf).Encode(original))
+
+	decoded := NewCounter().WithClock(clock)
+	testza.AssertNoError(t, gob.NewDecoder(&buf).Decode(decoded))
+
+	testza.AssertEqual(t, original.Count(), decoded.Count())
+	testza.AssertEqual(t, original.TotalIncremented(), decoded.TotalIncremented())
+	testza.AssertEqual(t, original.TotalDecremented(), decoded.TotalDecremented())
+	testza.AssertEqual(t, original.WeightedCount(), decoded.WeightedCount())
+	testza.AssertEqual(t, original.State(), decoded.State())
+	testza.AssertEqual(t, original.Snapshot(time.Second), decoded.Snapshot(time.Second))
+	testza.AssertEqual(t, original.samples(), decoded.samples())
+	testza.AssertEqual(t, original.maxSamples, decoded.maxSamples)
+
+	t.Run("Without stats", func(t *testing.T) {
+		c := NewCounter().Start()
+		c.IncrementBy(42)
+
+		data, err := c.GobEncode()
+		testza.AssertNoError(t, err)
+
+		decoded := NewCounter()
+		testza.AssertNoError(t, decoded.GobDecode(data))
+		testza.AssertEqual(t, uint64(42), decoded.Count())
+		testza.AssertEqual(t, StateRunning, decoded.State())
+		testza.AssertNil(t, decoded.triggers)
+	})
+
+	t.Run("Stats configuration", func(t *testing.T) {
+		clock := newFakeClock()
+		light := NewCounter().WithClock(clock).WithLightStats().WithStrictStats().WithWarmup(2).WithMetadata(map[string]string{"region": "eu"}).Start()
+		diff := NewCounter().WithClock(clock).WithAdvancedStats().WithDiffStorage().Start()
+
+		for i := 0; i < 10; i++ {
+			clock.Advance(time.Duration(i+1) * 10 * time.Millisecond)
+			light.Increment()
+			diff.Increment()
+		}
+
+		for _, original := range []*Counter{light, diff} {
+			data, err := original.GobEncode()
+			testza.AssertNoError(t, err)
+
+			decoded := NewCounter().WithClock(clock)
+			testza.AssertNoError(t, decoded.GobDecode(data))
+
+			testza.AssertEqual(t, original.Snapshot(time.Second), decoded.Snapshot(time.Second))
+			testza.AssertEqual(t, original.lightStats, decoded.lightStats)
+			testza.AssertEqual(t, original.diffStorage, decoded.diffStorage)
+			testza.AssertEqual(t, original.strictStats, decoded.strictStats)
+			testza.AssertEqual(t, original.warmup, decoded.warmup)
+			testza.AssertEqual(t, original.samples(), decoded.samples())
+			testza.AssertEqual(t, original.CalculateMaximumRate(time.Second), decoded.CalculateMaximumRate(time.Second))
+			testza.AssertEqual(t, original.CalculateMinimumRate(time.Second), decoded.CalculateMinimumRate(time.Second))
+		}
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		data, err := original.GobEncode()
+		testza.AssertNoError(t, err)
+
+		c := NewCounter()
+		c.IncrementBy(7)
+
+		for i := 0; i < len(data); i++ {
+			testza.AssertNotNil(t, c.GobDecode(data[:i]))
+		}
+
+		testza.AssertNotNil(t, c.GobDecode([]byte("garbage")))
+		testza.AssertEqual(t, uint64(7), c.Count())
+	})
+
+	t.Run("Inconsistent", func(t *testing.T) {
+		for _, state := range []gobState{
+			{Version: 2},
+			{Version: gobVersion, State: 7},
+			{Version: gobVersion, State: StateRunning},
+			{Version: gobVersion, State: StateStopped, StartedAt: time.Unix(10, 0), StoppedAt: time.Unix(5, 0)},
+			{Version: gobVersion, MaxSamples: 1, Triggers: []time.Time{time.Unix(1, 0), time.Unix(2, 0)}},
+			{Version: gobVersion, Triggers: []time.Time{time.Unix(2, 0), time.Unix(1, 0)}},
+			{Version: gobVersion, SeedMinDiff: time.Second, SeedMaxDiff: time.Millisecond},
+		} {
+			var buf bytes.Buffer
+			testza.AssertNoError(t, gob.NewEncoder(&buf).Encode(state))
+			testza.AssertNotNil(t, NewCounter().GobDecode(buf.Bytes()))
+		}
+	})
+}
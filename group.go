@@ -0,0 +1,143 @@
+package counter
+
+import (
+	"sort"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// CounterGroup is a thread-safe set of named counters, e.g. one per endpoint, which can be reported together.
+type CounterGroup struct {
+	mutex    sync.Mutex
+	counters map[string]*Counter
+}
+
+// NewCounterGroup returns a new, empty CounterGroup.
+func NewCounterGroup() *CounterGroup {
+	return &CounterGroup{counters: make(map[string]*Counter)}
+}
+
+// Add adds the counter c to the group under name, replacing any counter with the same name.
+// It returns the group itself, so you can chain it.
+func (g *CounterGroup) Add(name string, c *Counter) *CounterGroup {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.counters[name] = c
+
+	return g
+}
+
+// Get returns the counter with the given name, or nil if there is none.
+func (g *CounterGroup) Get(name string) *Counter {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return g.counters[name]
+}
+
+// Remove removes the counter with the given name from the group.
+func (g *CounterGroup) Remove(name string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	delete(g.counters, name)
+}
+
+// Snapshot returns a snapshot of every counter in the group, keyed by name, with rates in `count / interval` of the interval set via WithDefaultInterval.
+// All snapshots are taken at the same point in time: the counters are locked together, so none of them changes while the snapshots are taken.
+// The returned map is not used by the group afterwards.
+func (g *CounterGroup) Snapshot() map[string]Snapshot {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	unlock := g.lockMembers()
+	defer unlock()
+
+	snapshots := make(map[string]Snapshot, len(g.counters))
+	for name, c := range g.counters {
+		snapshots[name] = c.snapshot(c.interval())
+	}
+
+	return snapshots
+}
+
+// AverageRate calculates the combined average rate of all counters in the group, in `count / interval`.
+// The counts of all counters are summed up and divided by a common time span, from the earliest start of a counter
+// to the latest end of the measured time span of a counter, which is now if any counter is still running.
+// A counter that started later than the others therefore contributes its count, but doesn't shorten the time span.
+// Counters that were never started are ignored. Counters that are in the group under several names are only counted once.
+func (g *CounterGroup) AverageRate(interval time.Duration) float64 {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	unlock := g.lockMembers()
+	defer unlock()
+
+	var (
+		count       uint64
+		from, until time.Time
+	)
+
+	for _, c := range g.members() {
+		if c.startedAt.IsZero() {
+			continue
+		}
+
+		if c.count > c.rateBase {
+			count = addSaturating(count, c.count-c.rateBase)
+		}
+
+		if from.IsZero() || c.startedAt.Before(from) {
+			from = c.startedAt
+		}
+
+		if end := c.until(); end.After(until) {
+			until = end
+		}
+	}
+
+	elapsed := until.Sub(from)
+	if count == 0 || elapsed <= 0 {
+		return 0
+	}
+
+	return float64(count) / float64(elapsed) * float64(interval)
+}
+
+// members returns the counters in the group, each one only once, even if it is in the group under several names.
+// They are sorted by address, which is the order in which counters are locked together, like in CopyFrom.
+// It must be called with g.mutex held.
+func (g *CounterGroup) members() []*Counter {
+	members := make([]*Counter, 0, len(g.counters))
+	seen := make(map[*Counter]bool, len(g.counters))
+
+	for _, c := range g.counters {
+		if !seen[c] {
+			seen[c] = true
+			members = append(members, c)
+		}
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return uintptr(unsafe.Pointer(members[i])) < uintptr(unsafe.Pointer(members[j]))
+	})
+
+	return members
+}
+
+// lockMembers locks all counters in the group, in an order that prevents deadlocks with concurrent calls.
+// It must be called with g.mutex held. The returned function unlocks the counters again.
+func (g *CounterGroup) lockMembers() (unlock func()) {
+	members := g.members()
+	for _, c := range members {
+		c.mutex.Lock()
+	}
+
+	return func() {
+		for _, c := range members {
+			c.mutex.Unlock()
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package counter
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestCounterGroup(t *testing.T) {
+	requests := NewCounter()
+	errors := NewCounter()
+	g := NewCounterGroup().Add("requests", requests).Add("errors", errors)
+
+	testza.AssertEqual(t, requests, g.Get("requests"))
+	testza.AssertNil(t, g.Get("missing"))
+
+	g.Remove("errors")
+	testza.AssertNil(t, g.Get("errors"))
+}
+
+func TestCounterGroup_Snapshot(t *testing.T) {
+	clock := newFakeClock()
+	g := NewCounterGroup()
+
+	counters := make([]*Counter, 4)
+	for i := range counters {
+		counters[i] = NewCounter().WithClock(clock).WithAdvancedStats().Start()
+		g.Add(string(rune('a'+i)), counters[i])
+	}
+
+	// the same counter under a second name must not deadlock
+	g.Add("alias", counters[0])
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	for _, c := range counters {
+		wg.Add(1)
+
+		go func(c *Counter) {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					c.IncrementBy(2)
+					c.Decrement()
+				}
+			}
+		}(c)
+	}
+
+	for i := 0; i < 100; i++ {
+		clock.Advance(time.Millisecond)
+
+		snapshots := g.Snapshot()
+		testza.AssertLen(t, snapshots, 5)
+		testza.AssertEqual(t, snapshots["a"], snapshots["alias"])
+
+		for name, s := range snapshots {
+			testza.AssertTrue(t, s.Running, name)
+			testza.AssertEqual(t, time.Second, s.Interval, name)
+			// the average rate is calculated from the nanoseconds, so it may differ in the last digits
+			rate := float64(s.Count) / s.Elapsed.Seconds()
+			epsilon := max(rate*1e-9, 1e-9)
+			testza.AssertInRange(t, s.AverageRate, rate-epsilon, rate+epsilon, name)
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+}
+
+func TestCounterGroup_AverageRate(t *testing.T) {
+	clock := newFakeClock()
+	fast := NewCounter().WithClock(clock).Start()
+	slow := NewCounter().WithClock(clock).Start()
+	g := NewCounterGroup().Add("fast", fast).Add("slow", slow).Add("idle", NewCounter())
+
+	for i := 0; i < 10; i++ {
+		clock.Advance(100 * time.Millisecond)
+		fast.IncrementBy(3)
+		slow.Increment()
+	}
+
+	sum := fast.CalculateAverageRate(time.Second) + slow.CalculateAverageRate(time.Second)
+	testza.AssertInRange(t, sum, 40-1e-9, 40+1e-9)
+	testza.AssertInRange(t, g.AverageRate(time.Second), sum-1e-9, sum+1e-9)
+
+	// a counter in the group under two names is counted once
+	g.Add("alias", fast)
+	testza.AssertInRange(t, g.AverageRate(time.Second), 40-1e-9, 40+1e-9)
+
+	// a late counter doesn't shorten the time span
+	late := NewCounter().WithClock(clock).Start()
+	g.Add("late", late)
+	clock.Advance(time.Second)
+	late.IncrementBy(20)
+	testza.AssertInRange(t, g.AverageRate(time.Second), 30-1e-9, 30+1e-9)
+
+	testza.AssertEqual(t, 0.0, NewCounterGroup().AverageRate(time.Second))
+}
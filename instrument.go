@@ -0,0 +1,48 @@
+package counter
+
+import "time"
+
+// Instrument returns a function that increments c and calls fn, each time it's called.
+// It can be used to count calls of a function, without changing the function itself.
+func Instrument(c *Counter, fn func()) func() {
+	return func() {
+		c.Increment()
+		fn()
+	}
+}
+
+// InstrumentFunc is like Instrument, but for functions with an argument and a return value.
+func InstrumentFunc[T, R any](c *Counter, fn func(T) R) func(T) R {
+	return func(arg T) R {
+		c.Increment()
+		return fn(arg)
+	}
+}
+
+// CountChannel returns a channel that forwards all elements of in, and increments c for each forwarded element.
+// The returned channel is closed after in is closed and all of its elements were forwarded.
+func CountChannel[T any](c *Counter, in <-chan T) <-chan T {
+	out := make(chan T)
+
+	go func() {
+		defer close(out)
+
+		for v := range in {
+			out <- v
+			c.Increment()
+		}
+	}()
+
+	return out
+}
+
+// Measure creates and starts a new counter, passes it to fn, and stops it when fn returns.
+// It returns a snapshot of the counter with rates per second, e.g. to measure the throughput of a block of code.
+// The counter has advanced stats enabled, so the snapshot includes the minimum and maximum rate.
+func Measure(fn func(c *Counter)) Snapshot {
+	c := NewCounter().WithAdvancedStats().Start()
+	fn(c)
+	c.Stop()
+
+	return c.Snapshot(time.Second)
+}
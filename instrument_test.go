@@ -0,0 +1,73 @@
+package counter_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+
+	"atomicgo.dev/counter"
+)
+
+func TestInstrument(t *testing.T) {
+	c := counter.NewCounter().Start()
+
+	var calls int
+	fn := counter.Instrument(c, func() { calls++ })
+
+	for i := 0; i < 25; i++ {
+		fn()
+	}
+
+	testza.AssertEqual(t, uint64(25), c.Count())
+	testza.AssertEqual(t, 25, calls)
+}
+
+func TestInstrumentFunc(t *testing.T) {
+	c := counter.NewCounter().Start()
+	itoa := counter.InstrumentFunc(c, strconv.Itoa)
+
+	for i := 0; i < 10; i++ {
+		testza.AssertEqual(t, strconv.Itoa(i), itoa(i))
+	}
+
+	testza.AssertEqual(t, uint64(10), c.Count())
+}
+
+func TestCountChannel(t *testing.T) {
+	c := counter.NewCounter().Start()
+
+	in := make(chan int)
+	go func() {
+		defer close(in)
+
+		for i := 0; i < 100; i++ {
+			in <- i
+		}
+	}()
+
+	var received []int
+	for v := range counter.CountChannel(c, in) {
+		received = append(received, v)
+	}
+
+	testza.AssertLen(t, received, 100)
+	testza.AssertEqual(t, 99, received[99])
+	testza.AssertEqual(t, uint64(100), c.Count())
+}
+
+func TestMeasure(t *testing.T) {
+	s := counter.Measure(func(c *counter.Counter) {
+		for i := 0; i < 100; i++ {
+			c.Increment()
+			time.Sleep(100 * time.Microsecond)
+		}
+	})
+
+	testza.AssertEqual(t, uint64(100), s.Count)
+	testza.AssertFalse(t, s.Running)
+	testza.AssertGreaterOrEqual(t, s.Elapsed.Seconds(), 0.01)
+	testza.AssertInRange(t, s.AverageRate, 1.0, 10_000.0)
+	testza.AssertGreater(t, s.MaximumRate, s.MinimumRate)
+}
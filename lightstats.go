@@ -0,0 +1,59 @@
+package counter
+
+import "time"
+
+// WithLightStats enables the calculation of the minimum and maximum rate, like WithAdvancedStats, but with constant memory.
+// Instead of recording every increment, the counter only keeps the shortest and longest duration between consecutive increments.
+// Statistics that need the recorded increments, like CalculateJitter, WindowedMaxRate or WriteCSV, are not available and return their zero value.
+// Increments recorded via IncrementAt that are older than the newest one are not considered,
+// and neither are the durations between increments that share a timestamp due to a coarse clock resolution.
+func (c *Counter) WithLightStats() *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.enableStats = true
+	c.lightStats = true
+	c.triggers = nil
+	c.gaps = nil
+	c.ringHead = 0
+
+	return c
+}
+
+// recordLight records an increment at t for the light stats.
+// It must be called with c.mutex held.
+func (c *Counter) recordLight(t time.Time) {
+	if c.lightSamples > 0 {
+		if t.Before(c.lightLast) {
+			return
+		}
+
+		if c.lightLast.Before(c.extremesFrom) {
+			// the first increment after ResetExtremes
+			c.lightSamples++
+			c.lightLast = t
+
+			return
+		}
+
+		diff := t.Sub(c.lightLast)
+		if diff <= 0 {
+			// increments sharing a timestamp, due to a coarse clock resolution, are not considered
+			c.lightSamples++
+			return
+		}
+
+		if !c.lightHasDiff || diff < c.lightMinDiff {
+			c.lightMinDiff = diff
+		}
+
+		if !c.lightHasDiff || diff > c.lightMaxDiff {
+			c.lightMaxDiff = diff
+		}
+
+		c.lightHasDiff = true
+	}
+
+	c.lightSamples++
+	c.lightLast = t
+}
@@ -0,0 +1,43 @@
+package counter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestCounter_WithLightStats(t *testing.T) {
+	clock := newFakeClock()
+	full := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+	light := NewCounter().WithClock(clock).WithLightStats().Start()
+
+	for _, d := range []time.Duration{10, 30, 5, 50, 20, 1, 40, 15} {
+		clock.Advance(d * time.Millisecond)
+		full.Increment()
+		light.Increment()
+	}
+
+	testza.AssertEqual(t, full.CalculateAverageRate(time.Second), light.CalculateAverageRate(time.Second))
+	testza.AssertEqual(t, full.CalculateMinimumRate(time.Second), light.CalculateMinimumRate(time.Second))
+	testza.AssertEqual(t, full.CalculateMaximumRate(time.Second), light.CalculateMaximumRate(time.Second))
+	testza.AssertEqual(t, full.SampleCount(), light.SampleCount())
+
+	// nothing is retained per increment
+	testza.AssertNil(t, light.triggers)
+	testza.AssertLess(t, light.MemoryUsage(), full.MemoryUsage())
+	testza.AssertEqual(t, time.Duration(0), light.CalculateJitter())
+
+	// increments out of order are ignored
+	light.IncrementAt(clock.Now().Add(-time.Millisecond))
+	testza.AssertEqual(t, full.CalculateMaximumRate(time.Second), light.CalculateMaximumRate(time.Second))
+
+	light.Reset()
+	testza.AssertEqual(t, 0, light.SampleCount())
+	testza.AssertEqual(t, 0.0, light.CalculateMaximumRate(time.Second))
+
+	// a single increment has no rate yet
+	light.Start()
+	light.Increment()
+	testza.AssertEqual(t, 0.0, light.CalculateMaximumRate(time.Second))
+}
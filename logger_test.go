@@ -0,0 +1,43 @@
+package counter_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+
+	"atomicgo.dev/counter"
+)
+
+func TestCounter_WithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})).With("counter", "requests")
+
+	c := counter.NewCounter().WithLogger(logger).Start()
+	c.Increment()
+	c.Increment()
+	c.Stop()
+
+	type entry struct {
+		Level   string `json:"level"`
+		Msg     string `json:"msg"`
+		Counter string `json:"counter"`
+		Count   uint64 `json:"count"`
+	}
+
+	var entries []entry
+
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var e entry
+		testza.AssertNoError(t, dec.Decode(&e))
+		entries = append(entries, e)
+	}
+
+	testza.AssertEqual(t, []entry{
+		{Level: "DEBUG", Msg: "counter started", Counter: "requests", Count: 0},
+		{Level: "DEBUG", Msg: "counter stopped", Counter: "requests", Count: 2},
+	}, entries)
+}
@@ -0,0 +1,102 @@
+package counter
+
+import (
+	"container/heap"
+	"time"
+)
+
+// durationHeap is a min-heap of durations.
+type durationHeap []time.Duration
+
+func (h durationHeap) Len() int           { return len(h) }
+func (h durationHeap) Less(i, j int) bool { return h[i] < h[j] }
+func (h durationHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *durationHeap) Push(x any)        { *h = append(*h, x.(time.Duration)) }
+
+func (h *durationHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+
+	return x
+}
+
+// runningMedian maintains the median of the durations between consecutive increments with two heaps:
+// lower is a max-heap, stored negated, of the smaller half, and upper a min-heap of the larger half.
+// Adding a duration costs O(log n), and reading the median O(1).
+type runningMedian struct {
+	lower durationHeap
+	upper durationHeap
+	last  time.Time
+}
+
+// observe adds the duration since the previous increment, if any, for an increment at t.
+// Increments older than the previous one are ignored.
+func (m *runningMedian) observe(t time.Time) {
+	if !m.last.IsZero() {
+		if t.Before(m.last) {
+			return
+		}
+
+		m.add(t.Sub(m.last))
+	}
+
+	m.last = t
+}
+
+// add adds d and rebalances the heaps, so that lower has as many durations as upper, or one more.
+func (m *runningMedian) add(d time.Duration) {
+	if m.lower.Len() == 0 || d <= -m.lower[0] {
+		heap.Push(&m.lower, -d)
+	} else {
+		heap.Push(&m.upper, d)
+	}
+
+	switch {
+	case m.lower.Len() > m.upper.Len()+1:
+		heap.Push(&m.upper, -heap.Pop(&m.lower).(time.Duration))
+	case m.upper.Len() > m.lower.Len():
+		heap.Push(&m.lower, -heap.Pop(&m.upper).(time.Duration))
+	}
+}
+
+// median returns the median duration, or the mean of the two middle durations for an even number of them.
+// It returns 0 if there are none.
+func (m *runningMedian) median() time.Duration {
+	switch {
+	case m.lower.Len() == 0:
+		return 0
+	case m.lower.Len() > m.upper.Len():
+		return -m.lower[0]
+	default:
+		return (-m.lower[0] + m.upper[0]) / 2
+	}
+}
+
+// WithRunningMedian maintains the median of the durations between consecutive increments while counting,
+// which is returned by RunningMedianInterval.
+// Each increment costs O(log n) and reading the median O(1), instead of sorting all recorded increments on every read,
+// but the durations are still kept, so memory grows by one time.Duration per increment until the next reset.
+// It does not need WithAdvancedStats. Increments recorded via IncrementAt that are older than the newest one are not considered.
+func (c *Counter) WithRunningMedian() *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.median = &runningMedian{}
+
+	return c
+}
+
+// RunningMedianInterval returns the median duration between consecutive increments, maintained via WithRunningMedian.
+// For an even number of durations, it returns the mean of the two middle ones.
+// It returns 0 if the running median is not enabled, or fewer than two increments were counted.
+func (c *Counter) RunningMedianInterval() time.Duration {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.median == nil {
+		return 0
+	}
+
+	return c.median.median()
+}
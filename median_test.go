@@ -0,0 +1,43 @@
+package counter
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestCounter_RunningMedianInterval(t *testing.T) {
+	testza.AssertEqual(t, time.Duration(0), NewCounter().RunningMedianInterval())
+
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithRunningMedian().Start()
+	c.Increment()
+	testza.AssertEqual(t, time.Duration(0), c.RunningMedianInterval())
+
+	r := rand.New(rand.NewSource(1))
+
+	var diffs []time.Duration
+	for i := 0; i < 500; i++ {
+		d := time.Duration(r.Intn(1000)) * time.Millisecond
+		diffs = append(diffs, d)
+
+		clock.Advance(d)
+		c.Increment()
+
+		sorted := slices.Clone(diffs)
+		slices.Sort(sorted)
+
+		want := sorted[len(sorted)/2]
+		if len(sorted)%2 == 0 {
+			want = (sorted[len(sorted)/2-1] + want) / 2
+		}
+
+		testza.AssertEqual(t, want, c.RunningMedianInterval())
+	}
+
+	c.Reset()
+	testza.AssertEqual(t, time.Duration(0), c.RunningMedianInterval())
+}
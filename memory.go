@@ -0,0 +1,84 @@
+package counter
+
+import (
+	"slices"
+	"time"
+	"unsafe"
+)
+
+// Compact releases memory that is held by the advanced stats, but not used anymore, e.g. after the recorded increments were trimmed.
+func (c *Counter) Compact() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.diffStorage {
+		c.compactGaps()
+		return
+	}
+
+	triggers := c.samples()
+	if cap(triggers) == len(triggers) {
+		return
+	}
+
+	if len(triggers) == 0 {
+		c.triggers = nil
+		return
+	}
+
+	c.triggers = slices.Clone(triggers)
+}
+
+// WithMaxSamples bounds the number of increments recorded by the advanced stats to n.
+// When the limit is reached, the oldest recorded increment is dropped for each new one.
+// A value of 0 or lower records all increments.
+func (c *Counter) WithMaxSamples(n int) *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.setMaxSamples(n)
+
+	return c
+}
+
+// WithMaxMemory bounds the memory used by the counter, as reported by MemoryUsage, to about the given number of bytes.
+// The number of increments recorded by the advanced stats is limited accordingly, like with WithMaxSamples.
+// At least one increment is always recorded.
+func (c *Counter) WithMaxMemory(bytes int) *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.setMaxSamples(max((bytes-int(unsafe.Sizeof(*c)))/c.sampleSize(), 1))
+
+	return c
+}
+
+// setMaxSamples bounds the number of recorded increments, dropping the oldest ones that exceed the new limit.
+// It must be called with c.mutex held.
+func (c *Counter) setMaxSamples(n int) {
+	c.maxSamples = max(n, 0)
+
+	triggers := c.samples()
+	if c.maxSamples > 0 && len(triggers) > c.maxSamples {
+		c.setSamples(slices.Clone(triggers[len(triggers)-c.maxSamples:]))
+	}
+}
+
+// sampleSize returns the memory used to record a single increment in bytes.
+// It must be called with c.mutex held.
+func (c *Counter) sampleSize() int {
+	if c.diffStorage {
+		return int(unsafe.Sizeof(time.Duration(0)))
+	}
+
+	return int(unsafe.Sizeof(time.Time{}))
+}
+
+// MemoryUsage returns an estimate of the memory used by the counter in bytes.
+// It includes the counter itself and the memory allocated for the advanced stats.
+func (c *Counter) MemoryUsage() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return int(unsafe.Sizeof(*c)) + cap(c.triggers)*int(unsafe.Sizeof(time.Time{})) + cap(c.gaps)*int(unsafe.Sizeof(time.Duration(0)))
+}
@@ -0,0 +1,179 @@
+package counter
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestCounter_Compact(t *testing.T) {
+	c := NewCounter().WithAdvancedStats().Start()
+	for i := 0; i < 10_000; i++ {
+		c.Increment()
+	}
+
+	c.triggers = c.triggers[len(c.triggers)-10:]
+	testza.AssertGreater(t, cap(c.triggers), 10)
+
+	first := c.FirstIncrementTime()
+	c.Compact()
+	testza.AssertEqual(t, 10, cap(c.triggers))
+	testza.AssertLen(t, c.triggers, 10)
+	testza.AssertEqual(t, first, c.FirstIncrementTime())
+
+	c.triggers = c.triggers[:0]
+	c.Compact()
+	testza.AssertEqual(t, 0, cap(c.triggers))
+}
+
+func TestCounter_MemoryUsage(t *testing.T) {
+	c := NewCounter().WithAdvancedStats().Start()
+	base := c.MemoryUsage()
+	testza.AssertGreater(t, base, 0)
+
+	for i := 0; i < 1000; i++ {
+		c.Increment()
+	}
+
+	usage := c.MemoryUsage()
+	testza.AssertGreaterOrEqual(t, usage, base+1000*24)
+
+	for i := 0; i < 1000; i++ {
+		c.Increment()
+	}
+
+	testza.AssertGreater(t, c.MemoryUsage(), usage)
+
+	c.Reset()
+	testza.AssertEqual(t, base, c.MemoryUsage())
+}
+
+func TestCounter_WithMaxSamples(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithAdvancedStats().WithMaxSamples(10).Start()
+	start := clock.Now()
+
+	for i := 0; i < 25; i++ {
+		clock.Advance(time.Second)
+		c.Increment()
+	}
+
+	testza.AssertEqual(t, uint64(25), c.Count())
+	testza.AssertLen(t, c.triggers, 10)
+	testza.AssertEqual(t, 10, cap(c.triggers))
+	testza.AssertEqual(t, start.Add(16*time.Second), c.FirstIncrementTime())
+	testza.AssertEqual(t, start.Add(25*time.Second), c.LastIncrementTime())
+	testza.AssertLen(t, c.DownsampledSeries(1), 1)
+
+	t.Run("Out of order", func(t *testing.T) {
+		c.IncrementAt(start.Add(20500 * time.Millisecond))
+		c.IncrementAt(start)
+
+		testza.AssertLen(t, c.triggers, 10)
+		testza.AssertEqual(t, start.Add(17*time.Second), c.FirstIncrementTime())
+		testza.AssertEqual(t, 2.0, c.CalculateMaximumRate(time.Second))
+		testza.AssertTrue(t, slices.IsSortedFunc(c.samples(), func(a, b time.Time) int { return a.Compare(b) }))
+	})
+}
+
+func TestCounter_WithMaxMemory(t *testing.T) {
+	const limit = 4096
+
+	c := NewCounter().WithAdvancedStats().WithMaxMemory(limit).Start()
+	for i := 0; i < 10_000; i++ {
+		c.Increment()
+		testza.AssertLessOrEqual(t, c.MemoryUsage(), limit)
+	}
+
+	testza.AssertEqual(t, uint64(10_000), c.Count())
+	testza.AssertGreater(t, c.MemoryUsage(), limit/2)
+
+	t.Run("Diff storage", func(t *testing.T) {
+		clock := newFakeClock()
+		c := NewCounter().WithClock(clock).WithAdvancedStats().WithDiffStorage().WithMaxMemory(limit).Start()
+		for i := 0; i < 10_000; i++ {
+			clock.Advance(time.Duration(i%7+1) * time.Millisecond)
+			c.Increment()
+			testza.AssertLessOrEqual(t, c.MemoryUsage(), limit)
+		}
+
+		testza.AssertGreater(t, c.MemoryUsage(), limit/2)
+
+		// the ring buffer keeps the newest increments in order
+		samples := c.samples()
+		testza.AssertTrue(t, slices.IsSortedFunc(samples, func(a, b time.Time) int { return a.Compare(b) }))
+		testza.AssertEqual(t, clock.Now(), samples[len(samples)-1])
+		testza.AssertEqual(t, 1000.0, c.CalculateMaximumRate(time.Second))
+	})
+
+	t.Run("Out of order", func(t *testing.T) {
+		for _, diff := range []bool{false, true} {
+			clock := newFakeClock()
+			c := NewCounter().WithClock(clock).WithAdvancedStats().WithMaxMemory(limit)
+			if diff {
+				c.WithDiffStorage()
+			}
+
+			// all but the first increment are older than the newest one
+			c.Start()
+			c.IncrementAt(clock.Now().Add(time.Hour))
+			for i := 0; i < 1000; i++ {
+				clock.Advance(time.Second)
+				c.IncrementAt(clock.Now())
+				testza.AssertLessOrEqual(t, c.MemoryUsage(), limit)
+			}
+
+			testza.AssertTrue(t, slices.IsSortedFunc(c.samples(), func(a, b time.Time) int { return a.Compare(b) }))
+		}
+	})
+}
+
+func TestCounter_ResetForReuse(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+
+	for i := 0; i < 100; i++ {
+		clock.Advance(time.Millisecond)
+		c.Increment()
+	}
+
+	usage := c.MemoryUsage()
+	c.ResetForReuse()
+	testza.AssertEqual(t, uint64(0), c.Count())
+	testza.AssertEqual(t, StateNeverStarted, c.State())
+	testza.AssertLen(t, c.triggers, 0)
+	testza.AssertEqual(t, usage, c.MemoryUsage())
+	testza.AssertEqual(t, 0.0, c.CalculateMaximumRate(time.Second))
+
+	c.Start()
+	clock.Advance(time.Millisecond)
+	c.Increment()
+	testza.AssertEqual(t, []time.Time{clock.Now()}, c.samples())
+}
+
+func BenchmarkResetForReuse(b *testing.B) {
+	for _, bench := range []struct {
+		name  string
+		reset func(c *Counter)
+	}{
+		{"Reset", (*Counter).Reset},
+		{"ResetForReuse", (*Counter).ResetForReuse},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			c := NewCounter().WithAdvancedStats()
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				c.Start()
+				for j := 0; j < 100; j++ {
+					c.Increment()
+				}
+
+				bench.reset(c)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package counter
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// MergeStats merges the counts and the advanced stats of the src counters into the counter, e.g. to report the rates of a counter split into shards.
+// The recorded increments are combined in chronological order, so the rate statistics afterwards are those of a single counter that received all increments.
+// The src counters are locked one at a time and not modified, so concurrent merges can't deadlock. The time span of the counter is kept.
+// The recorded increments are only merged if the counter has advanced stats enabled.
+// Metadata of the src counters is added for keys the counter has no metadata for.
+// A recorded increment represents as many increments as set via WithSampling, so the recorded increments of counters with a different
+// sampling can't be combined: if a src counter with advanced stats has a different sampling than the counter, MergeStats returns
+// ErrSamplingMismatch and leaves the counter unchanged.
+// Light stats set via WithLightStats don't keep the recorded increments to combine: if the counter or a src counter with advanced stats
+// uses them, while the other one has advanced stats enabled, MergeStats returns ErrLightStats and leaves the counter unchanged.
+func (c *Counter) MergeStats(src ...*Counter) error {
+	type shard struct {
+		count                    uint64
+		incremented, decremented uint64
+		enableStats, lightStats  bool
+		sampling                 uint64
+		triggers                 []time.Time
+		seedMinDiff, seedMaxDiff time.Duration
+		metadata                 map[string]string
+	}
+
+	shards := make([]shard, 0, len(src))
+
+	for _, s := range src {
+		if s == c {
+			continue
+		}
+
+		s.mutex.Lock()
+		shards = append(shards, shard{
+			count:       s.count,
+			incremented: s.totalIncremented,
+			decremented: s.totalDecremented,
+			enableStats: s.enableStats,
+			lightStats:  s.lightStats,
+			sampling:    s.sampling,
+			triggers:    slices.Clone(s.samples()),
+			seedMinDiff: s.seedMinDiff,
+			seedMaxDiff: s.seedMaxDiff,
+			metadata:    s.metadata,
+		})
+		s.mutex.Unlock()
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.enableStats {
+		for _, s := range shards {
+			if !s.enableStats {
+				continue
+			}
+
+			if s.lightStats || c.lightStats {
+				return fmt.Errorf("merging stats: %w", ErrLightStats)
+			}
+
+			if s.sampling != c.sampling {
+				return fmt.Errorf("merging stats sampled every %d increments into every %d: %w",
+					max(s.sampling, 1), max(c.sampling, 1), ErrSamplingMismatch)
+			}
+		}
+	}
+
+	triggers := c.samples()
+
+	for _, s := range shards {
+		c.count = addSaturating(c.count, s.count)
+		c.totalIncremented = addSaturating(c.totalIncremented, s.incremented)
+		c.totalDecremented = addSaturating(c.totalDecremented, s.decremented)
+		c.mergeMetadata(s.metadata)
+
+		if !c.enableStats {
+			continue
+		}
+
+		triggers = append(triggers, s.triggers...)
+
+		if s.seedMinDiff > 0 && (c.seedMinDiff == 0 || s.seedMinDiff < c.seedMinDiff) {
+			c.seedMinDiff = s.seedMinDiff
+		}
+
+		c.seedMaxDiff = max(c.seedMaxDiff, s.seedMaxDiff)
+	}
+
+	slices.SortStableFunc(triggers, func(a, b time.Time) int { return a.Compare(b) })
+
+	c.setSamples(triggers)
+	c.setMaxSamples(c.maxSamples)
+	c.notifyZero()
+
+	return nil
+}
+
+// mergeMetadata adds the entries of metadata for keys that the counter has no metadata for.
+// The metadata of the counter is replaced instead of modified, since CopyFrom shares it between counters.
+// It must be called with c.mutex held.
+func (c *Counter) mergeMetadata(metadata map[string]string) {
+	var merged map[string]string
+
+	for key, value := range metadata {
+		if _, ok := c.metadata[key]; ok {
+			continue
+		}
+
+		if merged == nil {
+			merged = maps.Clone(c.metadata)
+			if merged == nil {
+				merged = make(map[string]string, len(metadata))
+			}
+		}
+
+		merged[key] = value
+	}
+
+	if merged != nil {
+		c.metadata = merged
+	}
+}
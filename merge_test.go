@@ -0,0 +1,124 @@
+package counter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestCounter_MergeStats(t *testing.T) {
+	clock := newFakeClock()
+	single := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+	shards := []*Counter{
+		NewCounter().WithClock(clock).WithAdvancedStats().Start(),
+		NewCounter().WithClock(clock).WithAdvancedStats().Start(),
+	}
+
+	for i, d := range []time.Duration{10, 30, 5, 50, 20, 1, 40, 15} {
+		clock.Advance(d * time.Millisecond)
+		single.Increment()
+		shards[i%3%2].Increment()
+	}
+
+	merged := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+	testza.AssertNoError(t, merged.MergeStats(shards...))
+
+	testza.AssertEqual(t, single.Count(), merged.Count())
+	testza.AssertEqual(t, single.TotalIncremented(), merged.TotalIncremented())
+	testza.AssertEqual(t, single.triggers, merged.triggers)
+	testza.AssertEqual(t, single.CalculateMaximumRate(time.Second), merged.CalculateMaximumRate(time.Second))
+	testza.AssertEqual(t, single.CalculateMinimumRate(time.Second), merged.CalculateMinimumRate(time.Second))
+	testza.AssertEqual(t, single.CalculateJitter(), merged.CalculateJitter())
+	testza.AssertEqual(t, single.CalculateCoefficientOfVariation(), merged.CalculateCoefficientOfVariation())
+
+	// the shards are not modified
+	testza.AssertEqual(t, uint64(5), shards[0].Count())
+	testza.AssertEqual(t, uint64(3), shards[1].Count())
+
+	t.Run("Max samples", func(t *testing.T) {
+		bounded := NewCounter().WithAdvancedStats().WithMaxSamples(3)
+		testza.AssertNoError(t, bounded.MergeStats(shards...))
+		testza.AssertEqual(t, uint64(8), bounded.Count())
+		testza.AssertEqual(t, single.triggers[5:], bounded.triggers)
+	})
+
+	t.Run("Without stats", func(t *testing.T) {
+		plain := NewCounter()
+		testza.AssertNoError(t, plain.MergeStats(shards[0], plain, shards[1]))
+		testza.AssertEqual(t, uint64(8), plain.Count())
+		testza.AssertLen(t, plain.triggers, 0)
+	})
+}
+
+func TestCounter_MergeStats_Sampling(t *testing.T) {
+	clock := newFakeClock()
+	sampled := NewCounter().WithClock(clock).WithAdvancedStats().WithSampling(10).Start()
+	other := NewCounter().WithClock(clock).WithAdvancedStats().WithSampling(10).Start()
+
+	for i := 0; i < 100; i++ {
+		clock.Advance(time.Millisecond)
+		sampled.Increment()
+		other.Increment()
+	}
+
+	t.Run("Same sampling", func(t *testing.T) {
+		merged := NewCounter().WithClock(clock).WithAdvancedStats().WithSampling(10)
+		testza.AssertNoError(t, merged.MergeStats(sampled, other))
+		testza.AssertEqual(t, uint64(200), merged.Count())
+		testza.AssertLen(t, merged.triggers, 20)
+	})
+
+	t.Run("Different sampling", func(t *testing.T) {
+		merged := NewCounter().WithClock(clock).WithAdvancedStats()
+		merged.IncrementBy(5)
+
+		testza.AssertErrorIs(t, merged.MergeStats(sampled, other), ErrSamplingMismatch)
+		testza.AssertEqual(t, uint64(5), merged.Count())
+		testza.AssertLen(t, merged.triggers, 1)
+	})
+
+	t.Run("Without stats", func(t *testing.T) {
+		plain := NewCounter()
+		testza.AssertNoError(t, plain.MergeStats(sampled))
+		testza.AssertEqual(t, uint64(100), plain.Count())
+	})
+}
+
+func TestCounter_MergeStats_LightStats(t *testing.T) {
+	clock := newFakeClock()
+	full := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+	light := NewCounter().WithClock(clock).WithLightStats().Start()
+
+	for i := 0; i < 10; i++ {
+		clock.Advance(time.Millisecond)
+		full.Increment()
+		light.Increment()
+	}
+
+	t.Run("Into light stats", func(t *testing.T) {
+		merged := NewCounter().WithClock(clock).WithLightStats()
+		merged.IncrementBy(5)
+
+		testza.AssertErrorIs(t, merged.MergeStats(full), ErrLightStats)
+		testza.AssertEqual(t, uint64(5), merged.Count())
+		testza.AssertEqual(t, 0.0, merged.CalculateMaximumRate(time.Second))
+		testza.AssertLen(t, merged.triggers, 0)
+	})
+
+	t.Run("From light stats", func(t *testing.T) {
+		merged := NewCounter().WithClock(clock).WithAdvancedStats()
+
+		testza.AssertErrorIs(t, merged.MergeStats(full, light), ErrLightStats)
+		testza.AssertEqual(t, uint64(0), merged.Count())
+	})
+
+	t.Run("Without stats", func(t *testing.T) {
+		merged := NewCounter().WithClock(clock).WithLightStats()
+		testza.AssertNoError(t, merged.MergeStats(NewCounter()))
+
+		plain := NewCounter()
+		testza.AssertNoError(t, plain.MergeStats(light))
+		testza.AssertEqual(t, uint64(10), plain.Count())
+	})
+}
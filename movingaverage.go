@@ -0,0 +1,86 @@
+package counter
+
+import "time"
+
+// movingAverage keeps the last durations between consecutive increments in a ring buffer, with their running sum.
+type movingAverage struct {
+	diffs []time.Duration
+	// head is the index of the oldest duration, once the ring buffer is full.
+	head int
+	sum  time.Duration
+	last time.Time
+}
+
+// observe adds the duration since the previous increment, if any, for an increment at t.
+// Increments older than the previous one are ignored.
+func (m *movingAverage) observe(t time.Time) {
+	if !m.last.IsZero() {
+		if t.Before(m.last) {
+			return
+		}
+
+		m.add(t.Sub(m.last))
+	}
+
+	m.last = t
+}
+
+// add adds d, replacing the oldest duration once the ring buffer is full.
+func (m *movingAverage) add(d time.Duration) {
+	if len(m.diffs) < cap(m.diffs) {
+		m.diffs = append(m.diffs, d)
+		m.sum += d
+
+		return
+	}
+
+	m.sum += d - m.diffs[m.head]
+	m.diffs[m.head] = d
+	m.head = (m.head + 1) % len(m.diffs)
+}
+
+// rate returns the rate of the mean of the durations in `count / interval`, or 0 if there are none.
+func (m *movingAverage) rate(interval time.Duration) float64 {
+	if m.sum <= 0 {
+		return 0
+	}
+
+	return float64(len(m.diffs)) * float64(interval) / float64(m.sum)
+}
+
+// clear removes all durations, keeping the memory of the ring buffer.
+func (m *movingAverage) clear() {
+	m.diffs = m.diffs[:0]
+	m.head = 0
+	m.sum = 0
+	m.last = time.Time{}
+}
+
+// WithMovingAverage maintains a simple moving average of the last k durations between consecutive increments,
+// which CalculateMovingAverageRate turns into a rate. Unlike the exponential smoothing of CalculateCurrentRate,
+// every duration within the last k has the same weight, and older ones have none, so a step change of the rate
+// is fully reflected after k increments. It uses constant memory, and costs O(1) per increment.
+// Only the time of the previous increment is kept, not the recorded increments of WithAdvancedStats, which it doesn't need.
+// So the durations are taken in the order of arrival, and an increment via IncrementAt before the previous one adds none to the window.
+func (c *Counter) WithMovingAverage(k int) *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.movingAverage = &movingAverage{diffs: make([]time.Duration, 0, max(k, 1))}
+
+	return c
+}
+
+// CalculateMovingAverageRate calculates the rate of the mean of the last durations between consecutive increments,
+// kept via WithMovingAverage. It returns the rate in `count / interval`.
+// It returns 0 if the moving average is not enabled, or fewer than two increments were counted.
+func (c *Counter) CalculateMovingAverageRate(interval time.Duration) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.movingAverage == nil {
+		return 0
+	}
+
+	return c.movingAverage.rate(interval)
+}
@@ -0,0 +1,43 @@
+package counter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestCounter_WithMovingAverage(t *testing.T) {
+	testza.AssertEqual(t, 0.0, NewCounter().CalculateMovingAverageRate(time.Second))
+
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithMovingAverage(4).Start()
+	c.Increment()
+	testza.AssertEqual(t, 0.0, c.CalculateMovingAverageRate(time.Second))
+
+	for i := 0; i < 10; i++ {
+		clock.Advance(100 * time.Millisecond)
+		c.Increment()
+	}
+
+	testza.AssertEqual(t, 10.0, c.CalculateMovingAverageRate(time.Second))
+
+	// after a step change, the rate converges within 4 increments
+	previous := 10.0
+	for i := 0; i < 3; i++ {
+		clock.Advance(50 * time.Millisecond)
+		c.Increment()
+
+		rate := c.CalculateMovingAverageRate(time.Second)
+		testza.AssertGreater(t, rate, previous)
+		testza.AssertLess(t, rate, 20.0)
+		previous = rate
+	}
+
+	clock.Advance(50 * time.Millisecond)
+	c.Increment()
+	testza.AssertEqual(t, 20.0, c.CalculateMovingAverageRate(time.Second))
+
+	c.Reset()
+	testza.AssertEqual(t, 0.0, c.CalculateMovingAverageRate(time.Second))
+}
@@ -0,0 +1,36 @@
+package counter
+
+import "slices"
+
+// Observer is notified of the events of a counter it was added to via AddObserver.
+// The methods are called without holding the lock of the counter, so they may access the counter.
+type Observer interface {
+	// OnIncrement is called after each increment, with the count after it.
+	OnIncrement(newCount uint64)
+	// OnReset is called after each reset.
+	OnReset()
+	// OnStop is called after the counter was stopped.
+	OnStop()
+}
+
+// AddObserver registers o to be notified of the increments, resets and stops of the counter.
+// Unlike the callbacks of WithOnReset or FirstIncrement, observers can be removed again via RemoveObserver.
+// Observers are compared with ==, so o must be comparable, e.g. a pointer.
+func (c *Counter) AddObserver(o Observer) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// copy on write, so that pending notifications keep the observers they captured
+	c.observers = append(slices.Clip(c.observers), o)
+}
+
+// RemoveObserver removes o, which was registered via AddObserver. It does nothing if o is not registered.
+// A notification that is already in progress may still reach o.
+func (c *Counter) RemoveObserver(o Observer) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if i := slices.Index(c.observers, o); i >= 0 {
+		c.observers = slices.Delete(slices.Clone(c.observers), i, i+1)
+	}
+}
@@ -0,0 +1,49 @@
+package counter
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+type recordingObserver struct {
+	events []string
+	counts []uint64
+}
+
+func (o *recordingObserver) OnIncrement(newCount uint64) {
+	o.events = append(o.events, "increment")
+	o.counts = append(o.counts, newCount)
+}
+
+func (o *recordingObserver) OnReset() { o.events = append(o.events, "reset") }
+
+func (o *recordingObserver) OnStop() { o.events = append(o.events, "stop") }
+
+func TestCounter_AddObserver(t *testing.T) {
+	first, second := &recordingObserver{}, &recordingObserver{}
+
+	c := NewCounter()
+	c.AddObserver(first)
+	c.AddObserver(second)
+
+	c.Start()
+	c.Increment()
+	c.IncrementBy(2)
+	c.Stop()
+	c.Stop()
+	c.Reset()
+
+	for _, o := range []*recordingObserver{first, second} {
+		testza.AssertEqual(t, []string{"increment", "increment", "stop", "reset"}, o.events)
+		testza.AssertEqual(t, []uint64{1, 3}, o.counts)
+	}
+
+	c.RemoveObserver(first)
+	c.Start()
+	c.Increment()
+	c.ResetKeepRunning()
+
+	testza.AssertLen(t, first.events, 4)
+	testza.AssertEqual(t, []string{"increment", "increment", "stop", "reset", "increment", "reset"}, second.events)
+}
@@ -0,0 +1,67 @@
+package counter
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// IncrementAtRate increments the counter by 1, like Increment, but first blocks just long enough to keep
+// the average rate of the counter at or below targetRate in `count / interval`, e.g. to pace work to a target rate.
+// The increments are spread evenly over the time since the counter was started, so after a pause without increments,
+// the next ones don't wait until the average rate reached targetRate again.
+// It returns the error of ctx without incrementing, if ctx is done before the increment is due.
+// If the counter is not running, or targetRate or interval is not positive, it increments without waiting.
+// The rate is measured over the ActiveDuration, so due increments wait while the counter is paused via Pause.
+// The wait is measured by the clock set via WithClock, if it is a TimerClock. Concurrent callers share the target rate. An increment vetoed via WithPreIncrement is not counted, and not retried.
+func (c *Counter) IncrementAtRate(ctx context.Context, targetRate float64, interval time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for {
+		c.mutex.Lock()
+
+		wait := c.paceDelay(targetRate, interval)
+		if wait <= 0 {
+			after, _ := c.tryIncrement(1, time.Time{})
+			c.mutex.Unlock()
+
+			after()
+
+			return nil
+		}
+
+		due := make(chan struct{})
+		timer := c.afterFunc(wait, func() { close(due) })
+		c.mutex.Unlock()
+
+		select {
+		case <-due:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// paceDelay returns how long the next increment has to wait, to keep the average rate at or below targetRate.
+// It must be called with c.mutex held.
+func (c *Counter) paceDelay(targetRate float64, interval time.Duration) time.Duration {
+	if c.state != StateRunning || targetRate <= 0 || interval <= 0 {
+		return 0
+	}
+
+	var next float64
+	if c.count > c.rateBase {
+		next = float64(c.count - c.rateBase)
+	}
+
+	// a very low target rate would overflow the duration
+	due := time.Duration(math.MaxInt64)
+	if d := (next + 1) / targetRate * float64(interval); d < math.MaxInt64 {
+		due = time.Duration(d)
+	}
+
+	return due - c.activeDuration(c.now())
+}
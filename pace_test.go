@@ -0,0 +1,55 @@
+package counter
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestCounter_IncrementAtRate(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).Start()
+
+	errs := make(chan error, 10)
+	go func() {
+		for i := 0; i < 10; i++ {
+			errs <- c.IncrementAtRate(context.Background(), 50, time.Second)
+		}
+	}()
+
+	// advance the clock to each scheduled increment
+	for c.Count() < 10 {
+		if d, ok := clock.untilNext(); ok {
+			clock.Advance(d)
+		} else {
+			runtime.Gosched()
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		testza.AssertNoError(t, <-errs)
+	}
+
+	testza.AssertEqual(t, 200*time.Millisecond, c.ActiveDuration())
+	testza.AssertEqual(t, 50.0, c.CalculateAverageRate(time.Second))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	testza.AssertErrorIs(t, c.IncrementAtRate(ctx, 1, time.Hour), context.DeadlineExceeded)
+	testza.AssertEqual(t, uint64(10), c.Count())
+}
+
+func TestCounter_IncrementAtRate_TinyRate(t *testing.T) {
+	c := NewCounter().Start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// the wait overflows a time.Duration, and must not turn negative
+	testza.AssertErrorIs(t, c.IncrementAtRate(ctx, 1e-300, time.Second), context.DeadlineExceeded)
+	testza.AssertEqual(t, uint64(0), c.Count())
+}
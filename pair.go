@@ -0,0 +1,52 @@
+package counter
+
+// PairCounter counts successes and failures, e.g. of requests, to report them together as an error rate.
+// Both counts are updated and read without locking, and don't collect any statistics; the zero value is ready to use.
+type PairCounter struct {
+	successes cell
+	failures  cell
+}
+
+// NewPairCounter returns a new PairCounter.
+func NewPairCounter() *PairCounter {
+	return &PairCounter{}
+}
+
+// IncrementSuccess increments the number of successes by 1.
+func (p *PairCounter) IncrementSuccess() {
+	p.successes.Add(1)
+}
+
+// IncrementFailure increments the number of failures by 1.
+func (p *PairCounter) IncrementFailure() {
+	p.failures.Add(1)
+}
+
+// Successes returns the number of successes.
+func (p *PairCounter) Successes() uint64 {
+	return p.successes.Load()
+}
+
+// Failures returns the number of failures.
+func (p *PairCounter) Failures() uint64 {
+	return p.failures.Load()
+}
+
+// Total returns the number of successes and failures.
+// Like FixedGroup.Total, it may include only some of the concurrent increments.
+func (p *PairCounter) Total() uint64 {
+	return p.successes.Load() + p.failures.Load()
+}
+
+// ErrorRate returns the fraction of failures of all counted outcomes, between 0 and 1.
+// It returns 0 if nothing was counted yet.
+func (p *PairCounter) ErrorRate() float64 {
+	failures := p.failures.Load()
+	total := failures + p.successes.Load()
+
+	if total == 0 {
+		return 0
+	}
+
+	return float64(failures) / float64(total)
+}
@@ -0,0 +1,39 @@
+package counter_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+
+	"atomicgo.dev/counter"
+)
+
+func TestPairCounter(t *testing.T) {
+	p := counter.NewPairCounter()
+	testza.AssertEqual(t, 0.0, p.ErrorRate())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				if j%4 == 0 {
+					p.IncrementFailure()
+				} else {
+					p.IncrementSuccess()
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	testza.AssertEqual(t, uint64(750), p.Successes())
+	testza.AssertEqual(t, uint64(250), p.Failures())
+	testza.AssertEqual(t, uint64(1000), p.Total())
+	testza.AssertEqual(t, 0.25, p.ErrorRate())
+}
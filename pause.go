@@ -0,0 +1,88 @@
+package counter
+
+import "time"
+
+// Pause pauses the measured time span of a running counter, e.g. while a job waits for user input.
+// The time until Resume is excluded from ActiveDuration and therefore from the rates, but included in TotalDuration.
+// The counter stays running, and increments while paused are still counted.
+// Pausing a paused counter, or a counter that is not running, does nothing.
+func (c *Counter) Pause() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.state != StateRunning || !c.pausedAt.IsZero() {
+		return
+	}
+
+	c.pausedAt = c.now()
+	c.log("counter paused")
+}
+
+// Resume resumes the measured time span of a counter paused via Pause.
+// Resuming a counter that is not paused does nothing. Stopping a paused counter ends the pause as well.
+func (c *Counter) Resume() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.pausedAt.IsZero() {
+		return
+	}
+
+	c.pausedTotal += max(c.now().Sub(c.pausedAt), 0)
+	c.pausedAt = time.Time{}
+	c.log("counter resumed")
+}
+
+// Paused reports whether the counter is paused via Pause.
+func (c *Counter) Paused() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return !c.pausedAt.IsZero()
+}
+
+// ActiveDuration returns the measured time span of the counter, excluding the pauses via Pause.
+// The average rates are calculated over it.
+// The time span ends now, or when the counter was stopped. It returns 0 if the counter has not been started yet.
+func (c *Counter) ActiveDuration() time.Duration {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.elapsed()
+}
+
+// TotalDuration returns the wall clock time since the counter was started, including the pauses via Pause,
+// e.g. to calculate its utilization as ActiveDuration / TotalDuration.
+// The time span ends now, or when the counter was stopped. It returns 0 if the counter has not been started yet.
+func (c *Counter) TotalDuration() time.Duration {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.startedAt.IsZero() {
+		return 0
+	}
+
+	return max(c.until().Sub(c.startedAt), 0)
+}
+
+// activeDuration returns the measured time span of the counter up to end, excluding pauses.
+// It must be called with c.mutex held.
+func (c *Counter) activeDuration(end time.Time) time.Duration {
+	if c.startedAt.IsZero() {
+		return 0
+	}
+
+	paused := c.pausedTotal
+	if !c.pausedAt.IsZero() && end.After(c.pausedAt) {
+		paused += end.Sub(c.pausedAt)
+	}
+
+	return max(end.Sub(c.startedAt)-paused, 0)
+}
+
+// clearPauses forgets all pauses, when the measured time span restarts.
+// It must be called with c.mutex held.
+func (c *Counter) clearPauses() {
+	c.pausedAt = time.Time{}
+	c.pausedTotal = 0
+}
@@ -0,0 +1,50 @@
+package counter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestCounter_Pause(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock)
+	c.Pause()
+	testza.AssertFalse(t, c.Paused())
+	testza.AssertEqual(t, time.Duration(0), c.TotalDuration())
+
+	c.Start()
+	clock.Advance(2 * time.Second)
+	c.IncrementBy(20)
+
+	c.Pause()
+	c.Pause()
+	testza.AssertTrue(t, c.Paused())
+	clock.Advance(3 * time.Second)
+	testza.AssertEqual(t, 2*time.Second, c.ActiveDuration())
+
+	c.Resume()
+	c.Resume()
+	testza.AssertFalse(t, c.Paused())
+	clock.Advance(2 * time.Second)
+	c.IncrementBy(20)
+
+	testza.AssertEqual(t, 4*time.Second, c.ActiveDuration())
+	testza.AssertEqual(t, 7*time.Second, c.TotalDuration())
+	testza.AssertEqual(t, 10.0, c.CalculateAverageRate(time.Second))
+
+	// stopping a paused counter ends the pause
+	c.Pause()
+	clock.Advance(time.Second)
+	c.Stop()
+	clock.Advance(time.Hour)
+	testza.AssertFalse(t, c.Paused())
+	testza.AssertEqual(t, 4*time.Second, c.ActiveDuration())
+	testza.AssertEqual(t, 8*time.Second, c.TotalDuration())
+
+	c.Start()
+	clock.Advance(time.Second)
+	testza.AssertEqual(t, time.Second, c.ActiveDuration())
+	testza.AssertEqual(t, time.Second, c.TotalDuration())
+}
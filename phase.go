@@ -0,0 +1,92 @@
+package counter
+
+import (
+	"sync"
+	"time"
+)
+
+// PhaseCounter counts the increments of a job with consecutive phases, like extract, transform and load,
+// and collects the count and rate of each phase on a shared timeline.
+type PhaseCounter struct {
+	mutex   sync.Mutex
+	clock   Clock
+	current *Counter
+	phases  map[string]*Counter
+}
+
+// NewPhaseCounter returns a new PhaseCounter, without a running phase.
+func NewPhaseCounter() *PhaseCounter {
+	return &PhaseCounter{phases: make(map[string]*Counter)}
+}
+
+// WithClock makes the phases read the current time from clock, instead of the system clock.
+func (p *PhaseCounter) WithClock(clock Clock) *PhaseCounter {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.clock = clock
+
+	return p
+}
+
+// BeginPhase ends the current phase, if any, and begins the phase with the given name.
+// No increment can fall between the two phases.
+// Beginning a phase that ran before replaces its statistics.
+func (p *PhaseCounter) BeginPhase(name string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.endPhase()
+
+	c := NewCounter()
+	if p.clock != nil {
+		c.WithClock(p.clock)
+	}
+
+	p.current = c.Start()
+	p.phases[name] = c
+}
+
+// EndPhase ends the current phase. Increments are not counted until the next phase begins.
+func (p *PhaseCounter) EndPhase() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.endPhase()
+}
+
+// endPhase ends the current phase, if any.
+// It must be called with p.mutex held.
+func (p *PhaseCounter) endPhase() {
+	if p.current == nil {
+		return
+	}
+
+	p.current.Stop()
+	p.current = nil
+}
+
+// Increment increments the count of the current phase by 1.
+// It does nothing if no phase is running.
+func (p *PhaseCounter) Increment() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.current != nil {
+		p.current.Increment()
+	}
+}
+
+// PhaseStats returns a snapshot of each phase that began so far, keyed by name, with rates per second.
+// The elapsed time of a phase is its duration, or the time since it began if it is still running.
+func (p *PhaseCounter) PhaseStats() map[string]Snapshot {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	stats := make(map[string]Snapshot, len(p.phases))
+	for name, c := range p.phases {
+		stats[name] = c.Snapshot(time.Second)
+	}
+
+	return stats
+}
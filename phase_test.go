@@ -0,0 +1,60 @@
+package counter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestPhaseCounter(t *testing.T) {
+	clock := newFakeClock()
+	p := NewPhaseCounter().WithClock(clock)
+
+	// not counted without a phase
+	p.Increment()
+	testza.AssertLen(t, p.PhaseStats(), 0)
+
+	for _, phase := range []struct {
+		name       string
+		increments int
+		duration   time.Duration
+	}{
+		{"extract", 100, 10 * time.Second},
+		{"transform", 50, 5 * time.Second},
+		{"load", 20, 4 * time.Second},
+	} {
+		p.BeginPhase(phase.name)
+
+		for i := 0; i < phase.increments; i++ {
+			clock.Advance(phase.duration / time.Duration(phase.increments))
+			p.Increment()
+		}
+	}
+
+	clock.Advance(time.Second)
+	stats := p.PhaseStats()
+	testza.AssertLen(t, stats, 3)
+
+	testza.AssertEqual(t, uint64(100), stats["extract"].Count)
+	testza.AssertEqual(t, 10*time.Second, stats["extract"].Elapsed)
+	testza.AssertEqual(t, 10.0, stats["extract"].AverageRate)
+	testza.AssertFalse(t, stats["extract"].Running)
+
+	testza.AssertEqual(t, uint64(50), stats["transform"].Count)
+	testza.AssertEqual(t, 10.0, stats["transform"].AverageRate)
+
+	// the last phase is still running
+	testza.AssertEqual(t, uint64(20), stats["load"].Count)
+	testza.AssertTrue(t, stats["load"].Running)
+	testza.AssertEqual(t, 5*time.Second, stats["load"].Elapsed)
+	testza.AssertEqual(t, 4.0, stats["load"].AverageRate)
+
+	p.EndPhase()
+	p.Increment()
+	clock.Advance(time.Second)
+	load := p.PhaseStats()["load"]
+	testza.AssertFalse(t, load.Running)
+	testza.AssertEqual(t, uint64(20), load.Count)
+	testza.AssertEqual(t, 5*time.Second, load.Elapsed)
+}
@@ -0,0 +1,59 @@
+package counter
+
+import "time"
+
+// ReadOnlyCounter is the part of a Counter that reports on it, without being able to change it.
+// It can be passed to code that only reports on the counter, e.g. an exporter, while other code increments it.
+type ReadOnlyCounter interface {
+	CountReader
+	State() State
+	Snapshot(interval time.Duration) Snapshot
+	CalculateAverageRate(interval time.Duration) float64
+	CalculateCurrentRate(interval time.Duration) float64
+	CalculateMinimumRate(interval time.Duration) float64
+	CalculateMaximumRate(interval time.Duration) float64
+}
+
+var (
+	_ ReadOnlyCounter = (*Counter)(nil)
+	_ ReadOnlyCounter = readOnlyCounter{}
+)
+
+// ReadOnly returns a read-only view of the counter.
+// Unlike the counter itself, the view can't be converted back into a *Counter via a type assertion.
+func (c *Counter) ReadOnly() ReadOnlyCounter {
+	return readOnlyCounter{c: c}
+}
+
+// readOnlyCounter only exposes the methods of ReadOnlyCounter of the wrapped counter.
+type readOnlyCounter struct {
+	c *Counter
+}
+
+func (r readOnlyCounter) Count() uint64 {
+	return r.c.Count()
+}
+
+func (r readOnlyCounter) State() State {
+	return r.c.State()
+}
+
+func (r readOnlyCounter) Snapshot(interval time.Duration) Snapshot {
+	return r.c.Snapshot(interval)
+}
+
+func (r readOnlyCounter) CalculateAverageRate(interval time.Duration) float64 {
+	return r.c.CalculateAverageRate(interval)
+}
+
+func (r readOnlyCounter) CalculateCurrentRate(interval time.Duration) float64 {
+	return r.c.CalculateCurrentRate(interval)
+}
+
+func (r readOnlyCounter) CalculateMinimumRate(interval time.Duration) float64 {
+	return r.c.CalculateMinimumRate(interval)
+}
+
+func (r readOnlyCounter) CalculateMaximumRate(interval time.Duration) float64 {
+	return r.c.CalculateMaximumRate(interval)
+}
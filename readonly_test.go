@@ -0,0 +1,32 @@
+package counter_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+
+	"atomicgo.dev/counter"
+)
+
+func TestCounter_ReadOnly(t *testing.T) {
+	c := counter.NewCounter().WithAdvancedStats().Start()
+	view := c.ReadOnly()
+
+	c.IncrementBy(5)
+	testza.AssertEqual(t, uint64(5), view.Count())
+	testza.AssertEqual(t, counter.StateRunning, view.State())
+	testza.AssertEqual(t, uint64(5), view.Snapshot(time.Second).Count)
+
+	_, ok := view.(*counter.Counter)
+	testza.AssertFalse(t, ok)
+
+	_, ok = view.(interface{ Increment() })
+	testza.AssertFalse(t, ok)
+
+	_, ok = view.(interface{ Reset() })
+	testza.AssertFalse(t, ok)
+
+	// a *Counter is a ReadOnlyCounter itself
+	var _ counter.ReadOnlyCounter = c
+}
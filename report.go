@@ -0,0 +1,134 @@
+package counter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WithRatePrecision rounds the rates reported by String, Report, Stats and RoundedRate to decimals decimal places,
+// e.g. 9.999999998/s to 10/s with a precision of 1. A negative precision rounds to tens, hundreds, and so on.
+// Only the reported rates are rounded; the rates returned by the Calculate methods are unchanged.
+func (c *Counter) WithRatePrecision(decimals int) *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.roundRates = true
+	c.ratePrecision = decimals
+
+	return c
+}
+
+// RoundedRate returns the average rate of the counter in `count / interval`, like CalculateAverageRate,
+// rounded to the precision set via WithRatePrecision.
+func (c *Counter) RoundedRate(interval time.Duration) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.round(c.averageRate(interval))
+}
+
+// String returns a short summary of the counter, like "42 (running, 10/s)".
+// The rate is the average rate, in the interval set via WithDefaultInterval.
+func (c *Counter) String() string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return fmt.Sprintf("%d (%s, %s)", c.count, c.state, c.formatRate(c.averageRate(c.interval())))
+}
+
+// Report returns a human-readable, multi-line report of the state and statistics of the counter.
+// The rates are shown in the interval set via WithDefaultInterval.
+// The minimum and maximum rate are only included with advanced stats enabled.
+func (c *Counter) Report() string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	interval := c.interval()
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "count:        %d\n", c.count)
+	fmt.Fprintf(&b, "state:        %s\n", c.state)
+	fmt.Fprintf(&b, "elapsed:      %s\n", c.elapsed())
+	fmt.Fprintf(&b, "average rate: %s\n", c.formatRate(c.averageRate(interval)))
+	fmt.Fprintf(&b, "current rate: %s\n", c.formatRate(c.currentRate(interval)))
+
+	if c.enableStats {
+		fmt.Fprintf(&b, "minimum rate: %s\n", c.formatRate(c.minimumRate(interval)))
+		fmt.Fprintf(&b, "maximum rate: %s\n", c.formatRate(c.maximumRate(interval)))
+	}
+
+	return b.String()
+}
+
+// Stats returns the state and statistics of the counter as a map, e.g. for generic metrics exporters, with rates in `count / interval`.
+// The rates are rounded to the precision set via WithRatePrecision.
+// It contains the keys "count", "elapsed_seconds", "avg_rate" and "current_rate".
+// With advanced stats enabled, it also contains "min_rate" and "max_rate"; otherwise these keys are omitted.
+func (c *Counter) Stats(interval time.Duration) map[string]float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := map[string]float64{
+		"count":           float64(c.count),
+		"elapsed_seconds": c.elapsed().Seconds(),
+		"avg_rate":        c.round(c.averageRate(interval)),
+		"current_rate":    c.round(c.currentRate(interval)),
+	}
+
+	if c.enableStats {
+		stats["min_rate"] = c.round(c.minimumRate(interval))
+		stats["max_rate"] = c.round(c.maximumRate(interval))
+	}
+
+	return stats
+}
+
+// formatRate formats a rate in the interval set via WithDefaultInterval, like "10/s", rounded like round.
+// It must be called with c.mutex held.
+func (c *Counter) formatRate(rate float64) string {
+	return strconv.FormatFloat(c.round(rate), 'f', -1, 64) + intervalSuffix(c.interval())
+}
+
+// round rounds a reported rate to the precision set via WithRatePrecision, if any.
+// A precision beyond the range of float64, where the scale or the scaled rate would overflow or underflow, leaves the rate unrounded.
+// It must be called with c.mutex held.
+func (c *Counter) round(rate float64) float64 {
+	if !c.roundRates {
+		return rate
+	}
+
+	scale := math.Pow10(c.ratePrecision)
+
+	scaled := rate * scale
+	if scale == 0 || math.IsInf(scale, 0) || math.IsInf(scaled, 0) {
+		return rate
+	}
+
+	return math.Round(scaled) / scale
+}
+
+// intervalSuffix returns the unit suffix of rates in `count / interval`, like "/s" or "/5m0s".
+func intervalSuffix(interval time.Duration) string {
+	switch interval {
+	case time.Nanosecond:
+		return "/ns"
+	case time.Microsecond:
+		return "/µs"
+	case time.Millisecond:
+		return "/ms"
+	case time.Second:
+		return "/s"
+	case time.Minute:
+		return "/min"
+	case time.Hour:
+		return "/h"
+	case 24 * time.Hour:
+		return "/day"
+	default:
+		return "/" + interval.String()
+	}
+}
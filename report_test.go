@@ -0,0 +1,106 @@
+package counter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestCounter_String(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock)
+	testza.AssertEqual(t, "0 (never started, 0/s)", c.String())
+
+	c.Start()
+	clock.Advance(2 * time.Second)
+	c.IncrementBy(5)
+	testza.AssertEqual(t, "5 (running, 2.5/s)", c.String())
+
+	c.WithDefaultInterval(time.Minute)
+	testza.AssertEqual(t, "5 (running, 150/min)", c.String())
+
+	c.WithDefaultInterval(5 * time.Minute)
+	testza.AssertEqual(t, "5 (running, 750/5m0s)", c.String())
+}
+
+func TestCounter_Report(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithDefaultInterval(time.Minute).Start()
+
+	for i := 0; i < 2; i++ {
+		clock.Advance(2 * time.Second)
+		c.IncrementBy(5)
+	}
+
+	c.Stop()
+
+	testza.AssertEqual(t, `count:        10
+state:        stopped
+elapsed:      4s
+average rate: 150/min
+current rate: 150/min
+`, c.Report())
+
+	c.WithDefaultInterval(time.Hour)
+	testza.AssertContains(t, c.Report(), "average rate: 9000/h\n")
+
+	c = NewCounter().WithClock(clock).WithAdvancedStats().Start()
+	c.Increment()
+	clock.Advance(500 * time.Millisecond)
+	c.Increment()
+	testza.AssertContains(t, c.Report(), "minimum rate: 2/s\nmaximum rate: 2/s\n")
+}
+
+func TestCounter_Stats(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).Start()
+
+	for i := 0; i < 4; i++ {
+		clock.Advance(500 * time.Millisecond)
+		c.Increment()
+	}
+
+	testza.AssertEqual(t, map[string]float64{
+		"count":           4,
+		"elapsed_seconds": 2,
+		"avg_rate":        2,
+		"current_rate":    2,
+	}, c.Stats(time.Second))
+
+	c.WithAdvancedStats()
+	clock.Advance(250 * time.Millisecond)
+	c.Increment()
+	clock.Advance(250 * time.Millisecond)
+	c.Increment()
+
+	stats := c.Stats(time.Minute)
+	testza.AssertEqual(t, 240.0, stats["min_rate"])
+	testza.AssertEqual(t, 240.0, stats["max_rate"])
+	testza.AssertEqual(t, 6.0, stats["count"])
+	testza.AssertLen(t, stats, 6)
+}
+
+func TestCounter_WithRatePrecision(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).Start()
+	clock.Advance(3 * time.Second)
+	c.Increment()
+	testza.AssertEqual(t, "1 (running, 0.3333333333333333/s)", c.String())
+
+	c.WithRatePrecision(1)
+	testza.AssertEqual(t, "1 (running, 0.3/s)", c.String())
+	testza.AssertContains(t, c.Report(), "average rate: 0.3/s\n")
+	testza.AssertEqual(t, 0.3, c.Stats(time.Second)["avg_rate"])
+	testza.AssertEqual(t, 0.3, c.RoundedRate(time.Second))
+	testza.AssertEqual(t, 1.0/3, c.CalculateAverageRate(time.Second))
+
+	c.WithRatePrecision(-1)
+	testza.AssertEqual(t, 20.0, c.RoundedRate(time.Minute))
+
+	// precisions beyond the range of float64 leave the rate unrounded
+	for _, decimals := range []int{400, 300, -400} {
+		c.WithRatePrecision(decimals)
+		testza.AssertEqual(t, 20.0, c.RoundedRate(time.Minute), decimals)
+	}
+}
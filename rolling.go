@@ -0,0 +1,136 @@
+package counter
+
+import "time"
+
+// rollingBuckets is a circular array of per-bucket counts, which is advanced lazily by time.
+type rollingBuckets struct {
+	size   time.Duration
+	counts []uint64
+	// sum is the sum of all counts.
+	sum uint64
+	// head is the index of the current bucket, which started at headStart.
+	head      int
+	headStart time.Time
+	// since is the start of the first bucket since the buckets were created or cleared.
+	since time.Time
+}
+
+// advance moves the current bucket forward to now, clearing all buckets that were skipped.
+func (r *rollingBuckets) advance(now time.Time) {
+	if r.headStart.IsZero() {
+		r.headStart = now
+		r.since = now
+
+		return
+	}
+
+	steps := now.Sub(r.headStart) / r.size
+	if steps <= 0 {
+		return
+	}
+
+	r.headStart = r.headStart.Add(steps * r.size)
+
+	if steps >= time.Duration(len(r.counts)) {
+		clear(r.counts)
+		r.sum = 0
+
+		return
+	}
+
+	for i := time.Duration(0); i < steps; i++ {
+		r.head = (r.head + 1) % len(r.counts)
+		r.sum -= r.counts[r.head]
+		r.counts[r.head] = 0
+	}
+}
+
+// add adds n to the bucket of now.
+func (r *rollingBuckets) add(now time.Time, n uint64) {
+	r.advance(now)
+	r.counts[r.head] = addSaturating(r.counts[r.head], n)
+	r.sum = addSaturating(r.sum, n)
+}
+
+// rate returns the rate of the increments within the buckets up to now, in `count / interval`.
+// The time span of the rate covers all buckets, but not the time before the first one, with the current bucket up to now.
+func (r *rollingBuckets) rate(now time.Time, interval time.Duration) float64 {
+	r.advance(now)
+
+	span := time.Duration(len(r.counts)-1)*r.size + now.Sub(r.headStart)
+	if since := now.Sub(r.since); since < span {
+		span = since
+	}
+
+	if span <= 0 {
+		return 0
+	}
+
+	return float64(r.sum) / float64(span) * float64(interval)
+}
+
+// get returns the counts of all buckets up to now, from the oldest to the current one.
+func (r *rollingBuckets) get(now time.Time) []uint64 {
+	r.advance(now)
+
+	counts := make([]uint64, 0, len(r.counts))
+	for i := 1; i <= len(r.counts); i++ {
+		counts = append(counts, r.counts[(r.head+i)%len(r.counts)])
+	}
+
+	return counts
+}
+
+// clear resets all buckets.
+func (r *rollingBuckets) clear() {
+	clear(r.counts)
+	r.sum = 0
+	r.head = 0
+	r.headStart = time.Time{}
+	r.since = time.Time{}
+}
+
+// WithRollingBuckets keeps the counts of the last numBuckets time windows of bucketSize each, e.g. for a live sparkline.
+// Updating the buckets costs O(1) per increment, reading them via RollingCounts O(numBuckets), and reading their rate via RollingRate O(1).
+// A bucketSize that is not positive is treated as one second, and numBuckets below 1 as 1.
+func (c *Counter) WithRollingBuckets(bucketSize time.Duration, numBuckets int) *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if bucketSize <= 0 {
+		bucketSize = time.Second
+	}
+
+	c.rolling = &rollingBuckets{size: bucketSize, counts: make([]uint64, max(numBuckets, 1))}
+
+	return c
+}
+
+// RollingCounts returns the number of increments within each of the rolling buckets,
+// from the oldest to the current one, which is still in progress.
+// It returns nil if rolling buckets are not enabled via WithRollingBuckets.
+func (c *Counter) RollingCounts() []uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.rolling == nil {
+		return nil
+	}
+
+	return c.rolling.get(c.now())
+}
+
+// RollingRate returns the rate of the increments within the rolling buckets, in `count / interval`,
+// e.g. the rate over the last minute with 60 buckets of a second. Increments expire from the rate bucket by bucket.
+// Unlike the windowed rates of the advanced stats, it keeps a running sum of the buckets, so it costs O(1) and needs no recorded increments.
+// It returns 0 if rolling buckets are not enabled via WithRollingBuckets.
+func (c *Counter) RollingRate(interval time.Duration) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.rolling == nil {
+		return 0
+	}
+
+	return c.rolling.rate(c.now(), interval)
+}
@@ -0,0 +1,91 @@
+package counter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestCounter_WithRollingBuckets(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithRollingBuckets(time.Second, 4).Start()
+	testza.AssertEqual(t, []uint64{0, 0, 0, 0}, c.RollingCounts())
+
+	for second := 1; second <= 3; second++ {
+		for i := 0; i < second; i++ {
+			c.Increment()
+		}
+		clock.Advance(time.Second)
+	}
+
+	c.IncrementBy(10)
+	testza.AssertEqual(t, []uint64{1, 2, 3, 10}, c.RollingCounts())
+
+	clock.Advance(2500 * time.Millisecond)
+	testza.AssertEqual(t, []uint64{3, 10, 0, 0}, c.RollingCounts())
+
+	c.Increment()
+	testza.AssertEqual(t, []uint64{3, 10, 0, 1}, c.RollingCounts())
+
+	clock.Advance(time.Hour)
+	testza.AssertEqual(t, []uint64{0, 0, 0, 0}, c.RollingCounts())
+
+	c.Increment()
+	c.Reset()
+	testza.AssertEqual(t, []uint64{0, 0, 0, 0}, c.RollingCounts())
+
+	testza.AssertNil(t, NewCounter().RollingCounts())
+}
+
+func TestCounter_WithRollingBuckets_InvalidSize(t *testing.T) {
+	for _, size := range []time.Duration{0, -time.Second} {
+		clock := newFakeClock()
+		c := NewCounter().WithClock(clock).WithRollingBuckets(size, 2).Start()
+
+		testza.AssertNotPanics(t, func() {
+			c.Increment()
+			clock.Advance(500 * time.Millisecond)
+			c.Increment()
+		})
+		testza.AssertEqual(t, []uint64{0, 2}, c.RollingCounts())
+
+		clock.Advance(time.Second)
+		testza.AssertEqual(t, []uint64{2, 0}, c.RollingCounts())
+	}
+}
+
+func TestCounter_RollingRate(t *testing.T) {
+	testza.AssertEqual(t, 0.0, NewCounter().RollingRate(time.Second))
+
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithRollingBuckets(time.Second, 4).Start()
+
+	c.IncrementBy(40)
+	clock.Advance(2 * time.Second)
+	testza.AssertEqual(t, 20.0, c.RollingRate(time.Second))
+
+	// the burst expires with its bucket, once the window moved past it
+	clock.Advance(1999 * time.Millisecond)
+	testza.AssertGreater(t, c.RollingRate(time.Second), 10.0)
+	clock.Advance(time.Millisecond)
+	testza.AssertEqual(t, 0.0, c.RollingRate(time.Second))
+
+	// the rate tracks a changing input
+	for i := 0; i < 40; i++ {
+		clock.Advance(100 * time.Millisecond)
+		c.Increment()
+	}
+
+	testza.AssertInRange(t, c.RollingRate(time.Second), 9.0, 11.0)
+
+	for i := 0; i < 8; i++ {
+		clock.Advance(500 * time.Millisecond)
+		c.Increment()
+	}
+
+	testza.AssertInRange(t, c.RollingRate(time.Second), 1.5, 2.5)
+
+	c.Reset()
+	testza.AssertEqual(t, 0.0, c.RollingRate(time.Second))
+}
@@ -0,0 +1,36 @@
+package counter
+
+import "math"
+
+// significantChange implements WithSignificantChange.
+type significantChange struct {
+	fraction float64
+	fn       func(rate float64)
+	last     float64
+}
+
+// observe updates the last reported rate with the current rate, if it differs from it by more than the fraction.
+// It reports whether it did.
+func (s *significantChange) observe(rate float64) bool {
+	if math.Abs(rate-s.last) <= s.fraction*s.last {
+		return false
+	}
+
+	s.last = rate
+
+	return true
+}
+
+// WithSignificantChange calls fn with the current rate in `count / interval`, with the interval set via WithDefaultInterval,
+// when it differs from the last rate fn was called with by more than fraction of it, e.g. 0.1 for 10%.
+// This keeps rate logs sparse: a steady rate is reported only once.
+// The current rate, as returned by CalculateCurrentRate, is evaluated on every increment; the first non-zero rate is always reported.
+// fn is called synchronously after the increment, without holding the lock of the counter.
+func (c *Counter) WithSignificantChange(fraction float64, fn func(rate float64)) *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.significant = &significantChange{fraction: fraction, fn: fn}
+
+	return c
+}
@@ -0,0 +1,32 @@
+package counter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestCounter_WithSignificantChange(t *testing.T) {
+	clock := newFakeClock()
+
+	var rates []float64
+	c := NewCounter().WithClock(clock).WithSignificantChange(0.1, func(rate float64) {
+		rates = append(rates, rate)
+	}).Start()
+
+	for i := 0; i < 10; i++ {
+		clock.Advance(100 * time.Millisecond)
+		c.Increment()
+	}
+
+	testza.AssertEqual(t, []float64{10}, rates)
+
+	for i := 0; i < 10; i++ {
+		clock.Advance(10 * time.Millisecond)
+		c.Increment()
+	}
+
+	testza.AssertGreater(t, len(rates), 1)
+	testza.AssertGreater(t, rates[len(rates)-1], 50.0)
+}
@@ -0,0 +1,149 @@
+package counter
+
+import (
+	"encoding/json"
+	"maps"
+	"time"
+)
+
+// Snapshot is a point-in-time view of the state and statistics of a Counter.
+// Its JSON encoding uses stable keys, with durations in nanoseconds.
+type Snapshot struct {
+	// Count is the count at the time of the snapshot.
+	Count uint64 `json:"count"`
+	// Running reports whether the counter was running.
+	Running bool `json:"running"`
+	// Elapsed is the measured time span of the counter.
+	Elapsed time.Duration `json:"elapsed_ns"`
+	// Interval is the interval of the rates.
+	Interval time.Duration `json:"interval_ns"`
+	// AverageRate is the average rate in `count / Interval`.
+	AverageRate float64 `json:"avg_rate"`
+	// MinimumRate is the minimum rate in `count / Interval`. It is 0 without advanced stats.
+	MinimumRate float64 `json:"min_rate"`
+	// MaximumRate is the maximum rate in `count / Interval`. It is 0 without advanced stats.
+	MaximumRate float64 `json:"max_rate"`
+	// Metadata are the labels set via WithMetadata, if any.
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// HumanDurations makes MarshalJSON encode the durations as strings like "1.5s", under the keys "elapsed" and "interval".
+	// It is not part of the encoding itself.
+	HumanDurations bool `json:"-"`
+}
+
+// MarshalJSON encodes the snapshot as JSON.
+// Durations are encoded as nanoseconds, or as strings if HumanDurations is set.
+func (s Snapshot) MarshalJSON() ([]byte, error) {
+	type snapshot Snapshot // without the MarshalJSON method
+
+	if !s.HumanDurations {
+		return json.Marshal(snapshot(s))
+	}
+
+	return json.Marshal(struct {
+		Count       uint64            `json:"count"`
+		Running     bool              `json:"running"`
+		Elapsed     string            `json:"elapsed"`
+		Interval    string            `json:"interval"`
+		AverageRate float64           `json:"avg_rate"`
+		MinimumRate float64           `json:"min_rate"`
+		MaximumRate float64           `json:"max_rate"`
+		Metadata    map[string]string `json:"metadata,omitempty"`
+	}{
+		Count:       s.Count,
+		Running:     s.Running,
+		Elapsed:     s.Elapsed.String(),
+		Interval:    s.Interval.String(),
+		AverageRate: s.AverageRate,
+		MinimumRate: s.MinimumRate,
+		MaximumRate: s.MaximumRate,
+		Metadata:    s.Metadata,
+	})
+}
+
+// Snapshot returns a consistent view of the state and statistics of the counter, with rates in `count / interval`.
+func (c *Counter) Snapshot(interval time.Duration) Snapshot {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.snapshot(interval)
+}
+
+// FreezeStats returns a snapshot of the counter with rates in the interval set via WithDefaultInterval, e.g. at a checkpoint.
+// Unlike Stop, it doesn't interrupt the counter, so two frozen snapshots can be compared via SnapshotDiff.
+func (c *Counter) FreezeStats() Snapshot {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.snapshot(c.interval())
+}
+
+// ResetAndSnapshot returns a snapshot of the counter with rates in `count / interval`, and resets it in the same step,
+// so that no increment is lost between the two, e.g. for interval reporting.
+// A running counter keeps running like with ResetKeepRunning; otherwise, it is reset like with Reset.
+func (c *Counter) ResetAndSnapshot(interval time.Duration) Snapshot {
+	c.mutex.Lock()
+
+	s := c.snapshot(interval)
+
+	if c.state == StateRunning {
+		c.startedAt = c.now()
+	} else {
+		c.startedAt = time.Time{}
+		c.stoppedAt = time.Time{}
+		c.state = StateNeverStarted
+	}
+
+	c.reset()
+	after := c.resetHooks()
+	c.mutex.Unlock()
+
+	after()
+
+	return s
+}
+
+// snapshot returns a snapshot of the counter.
+// It must be called with c.mutex held.
+func (c *Counter) snapshot(interval time.Duration) Snapshot {
+	return Snapshot{
+		Count:       c.count,
+		Running:     c.state == StateRunning,
+		Elapsed:     c.elapsed(),
+		Interval:    interval,
+		AverageRate: c.averageRate(interval),
+		MinimumRate: c.minimumRate(interval),
+		MaximumRate: c.maximumRate(interval),
+		Metadata:    maps.Clone(c.metadata),
+	}
+}
+
+// SnapshotDelta is the difference between two snapshots of the same counter.
+type SnapshotDelta struct {
+	// DeltaCount is the increase of the count.
+	DeltaCount uint64
+	// Duration is the measured time between the snapshots.
+	Duration time.Duration
+	// Rate is the rate between the snapshots, in `count / interval` of the later snapshot.
+	Rate float64
+}
+
+// SnapshotDiff returns the difference between the snapshots a and b, where b was taken after a.
+// If the counter was reset between the snapshots, the difference is computed from the reset on, so DeltaCount and Duration are those of b.
+func SnapshotDiff(a, b Snapshot) SnapshotDelta {
+	delta := SnapshotDelta{
+		DeltaCount: b.Count - a.Count,
+		Duration:   b.Elapsed - a.Elapsed,
+	}
+
+	if b.Count < a.Count || b.Elapsed < a.Elapsed {
+		delta.DeltaCount = b.Count
+		delta.Duration = b.Elapsed
+	}
+
+	if delta.Duration > 0 {
+		delta.Rate = float64(delta.DeltaCount) / float64(delta.Duration) * float64(b.Interval)
+	}
+
+	return delta
+}
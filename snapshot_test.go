@@ -0,0 +1,135 @@
+package counter
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestCounter_Snapshot(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+
+	for i := 0; i < 10; i++ {
+		clock.Advance(100 * time.Millisecond)
+		c.Increment()
+	}
+
+	testza.AssertEqual(t, Snapshot{
+		Count:       10,
+		Running:     true,
+		Elapsed:     time.Second,
+		Interval:    time.Second,
+		AverageRate: 10,
+		MinimumRate: 10,
+		MaximumRate: 10,
+	}, c.Snapshot(time.Second))
+}
+
+func TestCounter_FreezeStats(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithDefaultInterval(time.Minute).Start()
+
+	clock.Advance(time.Second)
+	c.IncrementBy(10)
+	first := c.FreezeStats()
+	testza.AssertEqual(t, c.Snapshot(time.Minute), first)
+
+	clock.Advance(time.Second)
+	c.IncrementBy(5)
+	second := c.FreezeStats()
+
+	testza.AssertEqual(t, uint64(10), first.Count)
+	testza.AssertEqual(t, uint64(15), second.Count)
+	testza.AssertTrue(t, second.Running)
+	testza.AssertEqual(t, SnapshotDelta{DeltaCount: 5, Duration: time.Second, Rate: 300}, SnapshotDiff(first, second))
+}
+
+func TestCounter_ResetAndSnapshot(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+
+	for i := 0; i < 10; i++ {
+		clock.Advance(100 * time.Millisecond)
+		c.Increment()
+	}
+
+	s := c.ResetAndSnapshot(time.Second)
+	testza.AssertEqual(t, uint64(10), s.Count)
+	testza.AssertEqual(t, time.Second, s.Elapsed)
+	testza.AssertEqual(t, 10.0, s.AverageRate)
+	testza.AssertEqual(t, 10.0, s.MaximumRate)
+
+	testza.AssertEqual(t, uint64(0), c.Count())
+	testza.AssertEqual(t, StateRunning, c.State())
+	testza.AssertEqual(t, 0.0, c.CalculateMaximumRate(time.Second))
+
+	// the next interval starts at the reset
+	clock.Advance(time.Second)
+	c.IncrementBy(5)
+	s = c.ResetAndSnapshot(time.Second)
+	testza.AssertEqual(t, uint64(5), s.Count)
+	testza.AssertEqual(t, time.Second, s.Elapsed)
+
+	c.Stop()
+	c.Increment()
+	s = c.ResetAndSnapshot(time.Second)
+	testza.AssertEqual(t, uint64(1), s.Count)
+	testza.AssertFalse(t, s.Running)
+	testza.AssertEqual(t, StateNeverStarted, c.State())
+}
+
+func TestSnapshot_MarshalJSON(t *testing.T) {
+	s := Snapshot{
+		Count:       15,
+		Running:     true,
+		Elapsed:     1500 * time.Millisecond,
+		Interval:    time.Second,
+		AverageRate: 10,
+		MinimumRate: 2.5,
+		MaximumRate: 20,
+	}
+
+	b, err := json.Marshal(s)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, `{"count":15,"running":true,"elapsed_ns":1500000000,"interval_ns":1000000000,"avg_rate":10,"min_rate":2.5,"max_rate":20}`, string(b))
+
+	var decoded Snapshot
+	testza.AssertNoError(t, json.Unmarshal(b, &decoded))
+	testza.AssertEqual(t, s, decoded)
+
+	s.HumanDurations = true
+	b, err = json.Marshal(s)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, `{"count":15,"running":true,"elapsed":"1.5s","interval":"1s","avg_rate":10,"min_rate":2.5,"max_rate":20}`, string(b))
+
+	s.Metadata = map[string]string{"env": "prod"}
+	b, err = json.Marshal(s)
+	testza.AssertNoError(t, err)
+	testza.AssertEqual(t, `{"count":15,"running":true,"elapsed":"1.5s","interval":"1s","avg_rate":10,"min_rate":2.5,"max_rate":20,"metadata":{"env":"prod"}}`, string(b))
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).Start()
+
+	clock.Advance(time.Second)
+	c.IncrementBy(5)
+	a := c.Snapshot(time.Second)
+
+	clock.Advance(2 * time.Second)
+	c.IncrementBy(40)
+	b := c.Snapshot(time.Second)
+
+	testza.AssertEqual(t, SnapshotDelta{DeltaCount: 40, Duration: 2 * time.Second, Rate: 20}, SnapshotDiff(a, b))
+
+	t.Run("Reset between snapshots", func(t *testing.T) {
+		c.ResetKeepRunning()
+		clock.Advance(time.Second)
+		c.IncrementBy(3)
+
+		testza.AssertEqual(t, SnapshotDelta{DeltaCount: 3, Duration: time.Second, Rate: 3}, SnapshotDiff(b, c.Snapshot(time.Second)))
+	})
+}
@@ -0,0 +1,452 @@
+package counter
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sort"
+	"time"
+)
+
+// CalculateJitter calculates how irregular the timing between increments is.
+// It returns the mean absolute deviation of the durations between consecutive increments from their mean.
+// A low jitter means the increments are evenly spaced.
+// With WithSampling, it is scaled down to approximate the jitter between single increments.
+// It returns 0 if fewer than three increments were recorded.
+// Needs to be enabled via WithAdvancedStats.
+func (c *Counter) CalculateJitter() time.Duration {
+	diffs, weight := c.copyDiffs()
+	if len(diffs) < 2 {
+		return 0
+	}
+
+	mean := meanDuration(diffs)
+
+	var deviation float64
+	for _, d := range diffs {
+		deviation += abs(float64(d) - mean)
+	}
+
+	return time.Duration(deviation / float64(len(diffs)) / weight)
+}
+
+// CalculateCoefficientOfVariation calculates how bursty the increments are, independent of their rate.
+// It returns the standard deviation of the durations between consecutive increments divided by their mean.
+// A perfectly regular stream has a coefficient of variation of 0.
+// It returns 0 if fewer than two increments were recorded.
+// Needs to be enabled via WithAdvancedStats.
+func (c *Counter) CalculateCoefficientOfVariation() float64 {
+	diffs, _ := c.copyDiffs()
+	if len(diffs) < 1 {
+		return 0
+	}
+
+	mean := meanDuration(diffs)
+	if mean == 0 {
+		return 0
+	}
+
+	var variance float64
+	for _, d := range diffs {
+		variance += (float64(d) - mean) * (float64(d) - mean)
+	}
+
+	variance /= float64(len(diffs))
+
+	return math.Sqrt(variance) / mean
+}
+
+// CalculatePercentileRate calculates the rate below which p percent of the rates between consecutive increments fall,
+// e.g. p = 50 for the median rate, or p = 99 for the rate of the fastest bursts. p is clamped to [0, 100].
+// It returns the rate in `count / interval`.
+// It returns 0 if fewer than two increments were recorded.
+// The recorded increments are copied under a brief lock and sorted outside of it, so concurrent increments are not blocked
+// by the sort; the copy temporarily allocates memory proportional to the number of recorded increments.
+// Needs to be enabled via WithAdvancedStats.
+func (c *Counter) CalculatePercentileRate(p float64, interval time.Duration) float64 {
+	diffs, weight := c.copyDiffs()
+	if len(diffs) == 0 {
+		return 0
+	}
+
+	// the fastest rates have the shortest durations, so sort the durations from the longest to the shortest
+	slices.SortFunc(diffs, func(a, b time.Duration) int { return cmp.Compare(b, a) })
+
+	rank := int(math.Ceil(min(max(p, 0), 100) / 100 * float64(len(diffs))))
+	d := diffs[max(rank, 1)-1]
+	if d <= 0 {
+		return 0
+	}
+
+	return weight * float64(interval) / float64(d)
+}
+
+// TrimmedMeanRate calculates a robust central rate of the counter, e.g. of a retrying client with bursts and backoff gaps.
+// It discards the shortest and the longest trimFraction of the durations between consecutive increments,
+// and returns the rate of the mean of the remaining ones, in `count / interval`.
+// A trimFraction of 0 discards nothing, and values of 0.5 and above discard everything.
+// It returns 0 if fewer than two increments were recorded, or no duration remains.
+// Like CalculatePercentileRate, it sorts a copy of the durations outside of the lock.
+// Needs to be enabled via WithAdvancedStats.
+func (c *Counter) TrimmedMeanRate(trimFraction float64, interval time.Duration) float64 {
+	diffs, weight := c.copyDiffs()
+
+	k := int(max(trimFraction, 0) * float64(len(diffs)))
+	if len(diffs)-2*k <= 0 {
+		return 0
+	}
+
+	slices.Sort(diffs)
+
+	mean := meanDuration(diffs[k : len(diffs)-k])
+	if mean <= 0 {
+		return 0
+	}
+
+	return weight * float64(interval) / mean
+}
+
+// WindowedMinRate calculates the minimum rate of the counter, only considering the increments within the trailing window.
+// The window ends now, or when the counter was stopped.
+// It returns the rate in `count / interval`.
+// It returns 0 if fewer than two increments were recorded within the window.
+// Needs to be enabled via WithAdvancedStats.
+func (c *Counter) WindowedMinRate(window, interval time.Duration) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	_, max := extremes(c.windowDiffs(window))
+	if max <= 0 {
+		return 0
+	}
+
+	return c.sampleWeight() * float64(interval) / float64(max)
+}
+
+// WindowedMaxRate calculates the maximum rate of the counter, only considering the increments within the trailing window.
+// The window ends now, or when the counter was stopped.
+// It returns the rate in `count / interval`.
+// It returns 0 if fewer than two increments were recorded within the window.
+// Needs to be enabled via WithAdvancedStats.
+func (c *Counter) WindowedMaxRate(window, interval time.Duration) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	min, _ := extremes(c.windowDiffs(window))
+	if min <= 0 {
+		return 0
+	}
+
+	return c.sampleWeight() * float64(interval) / float64(min)
+}
+
+// CompletedWindowsRate calculates the average rate of the counter over only the fully elapsed windows since it was started,
+// ignoring the current, incomplete one, e.g. for stable per-minute chart points.
+// The windows are aligned to the start of the counter, and end now, or when the counter was stopped.
+// It returns the rate in `count / interval`.
+// It returns 0 if no window is complete yet, or window is not positive.
+// Needs to be enabled via WithAdvancedStats.
+func (c *Counter) CompletedWindowsRate(window, interval time.Duration) float64 {
+	if window <= 0 {
+		return 0
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.enableStats || c.startedAt.IsZero() {
+		return 0
+	}
+
+	completed := c.until().Sub(c.startedAt) / window * window
+	if completed <= 0 {
+		return 0
+	}
+
+	triggers := c.samples()
+	end := c.startedAt.Add(completed)
+	from := sort.Search(len(triggers), func(i int) bool { return !triggers[i].Before(c.startedAt) })
+	to := sort.Search(len(triggers), func(i int) bool { return !triggers[i].Before(end) })
+
+	return float64(to-from) * c.sampleWeight() / float64(completed) * float64(interval)
+}
+
+// RateTrend returns the rates of the last points consecutive windows, oldest first, to fit a trend line to.
+// The last window ends now, or when the counter was stopped.
+// The rates are in `count / window`; windows without increments have a rate of 0.
+// It returns nil if window or points is not positive.
+// Needs to be enabled via WithAdvancedStats.
+func (c *Counter) RateTrend(window time.Duration, points int) []float64 {
+	if window <= 0 || points <= 0 {
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	rates := make([]float64, points)
+	if !c.enableStats {
+		return rates
+	}
+
+	until := c.until()
+	weight := c.sampleWeight()
+
+	for _, t := range c.samples() {
+		age := until.Sub(t)
+		if age < 0 {
+			continue
+		}
+
+		i := points - 1 - int(age/window)
+		if i < 0 {
+			continue
+		}
+
+		rates[i] += weight
+	}
+
+	return rates
+}
+
+// WithBaseline sets a known baseline rate in `count / interval`, to compare the counter against via DeviationFromBaseline.
+func (c *Counter) WithBaseline(baselineRate float64, interval time.Duration) *Counter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.baselineRate = baselineRate
+	c.baselineInterval = interval
+
+	return c
+}
+
+// DeviationFromBaseline returns the fractional difference between the current rate of the counter, as returned by CalculateCurrentRate,
+// and the baseline set via WithBaseline, so that it follows a drift of the throughput quickly.
+// For example, 0.1 means the counter is 10% faster than the baseline, and -0.5 means it's half as fast.
+// It returns 0 if no baseline is set, or the baseline is not finite.
+func (c *Counter) DeviationFromBaseline() float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.baselineRate == 0 {
+		return 0
+	}
+
+	return finite((c.currentRate(c.baselineInterval) - c.baselineRate) / c.baselineRate)
+}
+
+// MarkReference marks the current time as reference for CalculateRateSinceMark, e.g. when a new version was deployed.
+// Calling it again moves the mark. The mark is cleared by Reset.
+func (c *Counter) MarkReference() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.markAt = c.now()
+	c.markIncremented = c.totalIncremented
+}
+
+// CalculateRateSinceMark calculates the rate of the increments since the mark set via MarkReference,
+// without being affected by the increments before it. Decrements are not considered.
+// The time span ends now, or when the counter was stopped.
+// It returns the rate in `count / interval`.
+// It returns 0 if no mark is set.
+func (c *Counter) CalculateRateSinceMark(interval time.Duration) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.markAt.IsZero() {
+		return 0
+	}
+
+	elapsed := c.until().Sub(c.markAt)
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return float64(c.totalIncremented-c.markIncremented) / float64(elapsed) * float64(interval)
+}
+
+// EstimatedCompletion estimates when the count will reach target, assuming the average rate stays the same.
+// It returns the zero time if the target is already reached, or if the rate is 0.
+// If the target is too far away for a time.Duration, about 292 years, the estimate is capped at that.
+func (c *Counter) EstimatedCompletion(target uint64) time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.count >= target {
+		return time.Time{}
+	}
+
+	// the rate in count / nanosecond
+	rate := c.averageRate(1)
+	if rate <= 0 {
+		return time.Time{}
+	}
+
+	// a very slow rate would overflow the duration
+	remaining := time.Duration(math.MaxInt64)
+	if d := float64(target-c.count) / rate; d < math.MaxInt64 {
+		remaining = time.Duration(d)
+	}
+
+	return c.now().Add(remaining)
+}
+
+// SampleCount returns the number of increments recorded by the advanced stats, e.g. to decide whether the statistics are meaningful.
+// It is bounded by WithMaxSamples, and lower than the count with WithSampling.
+// With WithLightStats, it is the number of increments that were considered, even though they are not kept.
+// It returns 0 if advanced stats are not enabled via WithAdvancedStats.
+func (c *Counter) SampleCount() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.enableStats {
+		return 0
+	}
+
+	if c.lightStats {
+		return c.lightSamples
+	}
+
+	return c.recorded()
+}
+
+// FirstIncrementTime returns the time of the first recorded increment.
+// It returns the zero time if no increments were recorded.
+// Needs to be enabled via WithAdvancedStats.
+func (c *Counter) FirstIncrementTime() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.enableStats || c.recorded() == 0 {
+		return time.Time{}
+	}
+
+	return c.samples()[0]
+}
+
+// LastIncrementTime returns the time of the last recorded increment.
+// It returns the zero time if no increments were recorded.
+// Needs to be enabled via WithAdvancedStats.
+func (c *Counter) LastIncrementTime() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.enableStats || c.recorded() == 0 {
+		return time.Time{}
+	}
+
+	if c.diffStorage {
+		return c.gapsTo
+	}
+
+	return c.triggers[c.newest()]
+}
+
+// diffs returns the durations between consecutive recorded increments.
+// The returned slice must not be modified.
+// It must be called with c.mutex held.
+func (c *Counter) diffs() []time.Duration {
+	if c.diffStorage {
+		gaps := c.orderedGaps()
+		if len(gaps) < 2 {
+			return nil
+		}
+
+		return gaps[1:]
+	}
+
+	triggers := c.samples()
+	if len(triggers) < 2 {
+		return nil
+	}
+
+	diffs := make([]time.Duration, len(triggers)-1)
+	for i := 1; i < len(triggers); i++ {
+		diffs[i-1] = triggers[i].Sub(triggers[i-1])
+	}
+
+	return diffs
+}
+
+// copyDiffs returns a copy of the durations between consecutive recorded increments, and the weight of each of them.
+// It holds the lock only while copying, so expensive statistics can be computed from the copy
+// without blocking concurrent increments, at the cost of allocating one time.Duration per recorded increment.
+// It returns nil if advanced stats are not enabled.
+func (c *Counter) copyDiffs() ([]time.Duration, float64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.enableStats {
+		return nil, 0
+	}
+
+	if c.diffStorage {
+		return slices.Clone(c.diffs()), c.sampleWeight()
+	}
+
+	// diffs already returns a new slice for recorded timestamps
+	return c.diffs(), c.sampleWeight()
+}
+
+// windowDiffs returns the durations between consecutive recorded increments within the trailing window,
+// with increments sharing a timestamp coalesced like for CalculateMaximumRate.
+// It must be called with c.mutex held.
+func (c *Counter) windowDiffs(window time.Duration) []time.Duration {
+	if !c.enableStats {
+		return nil
+	}
+
+	triggers := c.samples()
+	from := c.until().Add(-window)
+	i := sort.Search(len(triggers), func(i int) bool { return !triggers[i].Before(from) })
+
+	var diffs []time.Duration
+	var ticks tickCoalescer
+
+	merge := func(diff time.Duration) { diffs = append(diffs, diff) }
+	for i++; i < len(triggers); i++ {
+		ticks.add(triggers[i].Sub(triggers[i-1]), merge)
+	}
+
+	ticks.flush(merge)
+
+	return diffs
+}
+
+// extremes returns the shortest and longest duration, or 0 if durations is empty.
+func extremes(durations []time.Duration) (min, max time.Duration) {
+	for i, d := range durations {
+		if d < min || i == 0 {
+			min = d
+		}
+
+		if d > max {
+			max = d
+		}
+	}
+
+	return min, max
+}
+
+// meanDuration returns the arithmetic mean of durations in nanoseconds.
+func meanDuration(durations []time.Duration) float64 {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, d := range durations {
+		sum += float64(d)
+	}
+
+	return sum / float64(len(durations))
+}
+
+func abs(f float64) float64 {
+	if f < 0 {
+		return -f
+	}
+
+	return f
+}
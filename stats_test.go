@@ -0,0 +1,321 @@
+package counter
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+// counterWithDiffs returns a counter with advanced stats, whose recorded increments are spaced by the given durations.
+func counterWithDiffs(diffs ...time.Duration) *Counter {
+	c := NewCounter().WithAdvancedStats()
+
+	t := time.Unix(0, 0)
+	c.triggers = append(c.triggers, t)
+
+	for _, d := range diffs {
+		t = t.Add(d)
+		c.triggers = append(c.triggers, t)
+	}
+
+	c.count = uint64(len(c.triggers))
+
+	return c
+}
+
+func TestCounter_CalculateJitter(t *testing.T) {
+	t.Run("Regular", func(t *testing.T) {
+		c := counterWithDiffs(10*time.Millisecond, 10*time.Millisecond, 10*time.Millisecond, 10*time.Millisecond)
+		testza.AssertEqual(t, time.Duration(0), c.CalculateJitter())
+	})
+
+	t.Run("Irregular", func(t *testing.T) {
+		c := counterWithDiffs(10*time.Millisecond, 30*time.Millisecond, 10*time.Millisecond, 30*time.Millisecond)
+		testza.AssertEqual(t, 10*time.Millisecond, c.CalculateJitter())
+	})
+
+	t.Run("Too few increments", func(t *testing.T) {
+		testza.AssertEqual(t, time.Duration(0), counterWithDiffs(10*time.Millisecond).CalculateJitter())
+	})
+
+	t.Run("Advanced stats disabled", func(t *testing.T) {
+		c := NewCounter().Start()
+		for i := 0; i < 10; i++ {
+			c.Increment()
+		}
+
+		testza.AssertEqual(t, time.Duration(0), c.CalculateJitter())
+	})
+}
+
+func TestCounter_CalculateCoefficientOfVariation(t *testing.T) {
+	t.Run("Regular", func(t *testing.T) {
+		c := counterWithDiffs(time.Second, time.Second, time.Second)
+		testza.AssertEqual(t, 0.0, c.CalculateCoefficientOfVariation())
+	})
+
+	t.Run("Scale invariant", func(t *testing.T) {
+		diffs := []time.Duration{time.Millisecond, 5 * time.Millisecond, 2 * time.Millisecond, 10 * time.Millisecond}
+
+		scaled := make([]time.Duration, len(diffs))
+		for i, d := range diffs {
+			scaled[i] = d * 7
+		}
+
+		cv := counterWithDiffs(diffs...).CalculateCoefficientOfVariation()
+		testza.AssertGreater(t, cv, 0.0)
+		testza.AssertInRange(t, counterWithDiffs(scaled...).CalculateCoefficientOfVariation(), cv-1e-9, cv+1e-9)
+	})
+
+	t.Run("Burstier is larger", func(t *testing.T) {
+		mild := counterWithDiffs(9*time.Millisecond, 11*time.Millisecond, 9*time.Millisecond, 11*time.Millisecond)
+		bursty := counterWithDiffs(time.Millisecond, 19*time.Millisecond, time.Millisecond, 19*time.Millisecond)
+		testza.AssertGreater(t, bursty.CalculateCoefficientOfVariation(), mild.CalculateCoefficientOfVariation())
+	})
+}
+
+func TestCounter_CalculatePercentileRate(t *testing.T) {
+	testza.AssertEqual(t, 0.0, counterWithDiffs().CalculatePercentileRate(50, time.Second))
+
+	c := counterWithDiffs(250*time.Millisecond, 100*time.Millisecond, 50*time.Millisecond, 100*time.Millisecond, 500*time.Millisecond)
+	testza.AssertEqual(t, 2.0, c.CalculatePercentileRate(0, time.Second))
+	testza.AssertEqual(t, 2.0, c.CalculatePercentileRate(20, time.Second))
+	testza.AssertEqual(t, 10.0, c.CalculatePercentileRate(50, time.Second))
+	testza.AssertEqual(t, 20.0, c.CalculatePercentileRate(100, time.Second))
+	testza.AssertEqual(t, 20.0, c.CalculatePercentileRate(200, time.Second))
+
+	// the recorded increments are not reordered by the sort
+	testza.AssertEqual(t, []time.Duration{250 * time.Millisecond, 100 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond, 500 * time.Millisecond}, c.diffs())
+}
+
+func TestCounter_TrimmedMeanRate(t *testing.T) {
+	testza.AssertEqual(t, 0.0, counterWithDiffs().TrimmedMeanRate(0.1, time.Second))
+
+	diffs := []time.Duration{10 * time.Millisecond, 5 * time.Second}
+	for i := 0; i < 8; i++ {
+		diffs = append(diffs, 100*time.Millisecond)
+	}
+
+	c := counterWithDiffs(diffs...)
+	testza.AssertEqual(t, 10.0, c.TrimmedMeanRate(0.1, time.Second))
+	testza.AssertLess(t, c.TrimmedMeanRate(0, time.Second), 2.0)
+	testza.AssertEqual(t, 0.0, c.TrimmedMeanRate(0.5, time.Second))
+}
+
+func TestCounter_CompletedWindowsRate(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+	testza.AssertEqual(t, 0.0, c.CompletedWindowsRate(time.Minute, time.Second))
+
+	// 2 increments per second for 2.5 minutes
+	for i := 0; i < 300; i++ {
+		c.Increment()
+		clock.Advance(500 * time.Millisecond)
+	}
+
+	testza.AssertEqual(t, 2.0, c.CompletedWindowsRate(time.Minute, time.Second))
+
+	// an idle, incomplete window doesn't pull the rate down; once it is complete, it does
+	clock.Advance(29 * time.Second)
+	testza.AssertEqual(t, 2.0, c.CompletedWindowsRate(time.Minute, time.Second))
+	clock.Advance(time.Second)
+	testza.AssertEqual(t, 5.0/3, c.CompletedWindowsRate(time.Minute, time.Second))
+}
+
+func TestCounter_SampleCount(t *testing.T) {
+	c := NewCounter().WithAdvancedStats().Start()
+	bounded := NewCounter().WithAdvancedStats().WithMaxSamples(10).Start()
+	diffs := NewCounter().WithAdvancedStats().WithDiffStorage().Start()
+	plain := NewCounter().Start()
+
+	for i := 0; i < 25; i++ {
+		c.Increment()
+		bounded.Increment()
+		diffs.Increment()
+		plain.Increment()
+	}
+
+	testza.AssertEqual(t, 25, c.SampleCount())
+	testza.AssertEqual(t, 10, bounded.SampleCount())
+	testza.AssertEqual(t, 25, diffs.SampleCount())
+	testza.AssertEqual(t, 0, plain.SampleCount())
+}
+
+func TestCounter_FirstAndLastIncrementTime(t *testing.T) {
+	c := NewCounter().WithAdvancedStats().Start()
+	testza.AssertTrue(t, c.FirstIncrementTime().IsZero())
+	testza.AssertTrue(t, c.LastIncrementTime().IsZero())
+
+	beforeFirst := time.Now()
+	c.Increment()
+	afterFirst := time.Now()
+
+	time.Sleep(5 * time.Millisecond)
+	c.Increment()
+	time.Sleep(5 * time.Millisecond)
+
+	beforeLast := time.Now()
+	c.Increment()
+	afterLast := time.Now()
+
+	first, last := c.FirstIncrementTime(), c.LastIncrementTime()
+	testza.AssertFalse(t, first.Before(beforeFirst))
+	testza.AssertFalse(t, first.After(afterFirst))
+	testza.AssertFalse(t, last.Before(beforeLast))
+	testza.AssertFalse(t, last.After(afterLast))
+
+	t.Run("Advanced stats disabled", func(t *testing.T) {
+		c := NewCounter().Start()
+		c.Increment()
+		testza.AssertTrue(t, c.FirstIncrementTime().IsZero())
+		testza.AssertTrue(t, c.LastIncrementTime().IsZero())
+	})
+}
+
+func TestCounter_WindowedRates(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+
+	// early burst: 10 increments, 1ms apart
+	for i := 0; i < 10; i++ {
+		clock.Advance(time.Millisecond)
+		c.Increment()
+	}
+
+	// steady stream: 20 increments, 100ms apart
+	for i := 0; i < 20; i++ {
+		clock.Advance(100 * time.Millisecond)
+		c.Increment()
+	}
+
+	testza.AssertEqual(t, 1000.0, c.CalculateMaximumRate(time.Second))
+	testza.AssertEqual(t, 1000.0, c.WindowedMaxRate(time.Hour, time.Second))
+	testza.AssertEqual(t, 10.0, c.WindowedMaxRate(time.Second, time.Second))
+	testza.AssertEqual(t, 10.0, c.WindowedMinRate(time.Second, time.Second))
+
+	clock.Advance(time.Minute)
+	testza.AssertEqual(t, 0.0, c.WindowedMaxRate(time.Second, time.Second))
+	testza.AssertEqual(t, 0.0, c.WindowedMinRate(time.Second, time.Second))
+	testza.AssertEqual(t, 0.0, NewCounter().Start().WindowedMaxRate(time.Hour, time.Second))
+}
+
+func TestCounter_RateTrend(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+
+	// ramp: 1 increment in the first second, 2 in the second, ...
+	for second := 1; second <= 5; second++ {
+		for i := 0; i < second; i++ {
+			clock.Advance(time.Second / time.Duration(second+1))
+			c.Increment()
+		}
+
+		clock.Advance(time.Second / time.Duration(second+1))
+	}
+
+	trend := c.RateTrend(time.Second, 5)
+	testza.AssertEqual(t, []float64{1, 2, 3, 4, 5}, trend)
+	testza.AssertTrue(t, slices.IsSorted(trend))
+
+	// older windows than the first increment are empty
+	testza.AssertEqual(t, []float64{0, 0, 1, 2, 3, 4, 5}, c.RateTrend(time.Second, 7))
+
+	testza.AssertNil(t, c.RateTrend(0, 5))
+	testza.AssertEqual(t, []float64{0, 0}, NewCounter().Start().RateTrend(time.Second, 2))
+}
+
+func TestCounter_CalculateRateSinceMark(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).Start()
+	testza.AssertEqual(t, 0.0, c.CalculateRateSinceMark(time.Second))
+
+	clock.Advance(time.Second)
+	c.IncrementBy(100)
+	c.MarkReference()
+	testza.AssertEqual(t, 0.0, c.CalculateRateSinceMark(time.Second))
+
+	clock.Advance(2 * time.Second)
+	c.IncrementBy(10)
+	c.Decrement()
+	testza.AssertEqual(t, 5.0, c.CalculateRateSinceMark(time.Second))
+	testza.AssertGreater(t, c.CalculateAverageRate(time.Second), 30.0)
+
+	// a stopped counter ends the time span
+	c.Stop()
+	clock.Advance(time.Hour)
+	testza.AssertEqual(t, 5.0, c.CalculateRateSinceMark(time.Second))
+
+	c.Reset()
+	testza.AssertEqual(t, 0.0, c.CalculateRateSinceMark(time.Second))
+}
+
+func TestCounter_DeviationFromBaseline(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithBaseline(100, time.Second).Start()
+
+	// 80 per second
+	for i := 0; i < 50; i++ {
+		clock.Advance(12500 * time.Microsecond)
+		c.Increment()
+	}
+
+	testza.AssertInRange(t, c.DeviationFromBaseline(), -0.2-1e-9, -0.2+1e-9)
+
+	// the rate drifts to 200 per second, which the average rate only follows slowly
+	for i := 0; i < 100; i++ {
+		clock.Advance(5 * time.Millisecond)
+		c.Increment()
+	}
+
+	testza.AssertLess(t, c.CalculateAverageRate(time.Second), 200.0)
+	testza.AssertInRange(t, c.DeviationFromBaseline(), 1-1e-6, 1+1e-6)
+
+	testza.AssertEqual(t, 0.0, NewCounter().WithClock(clock).Start().DeviationFromBaseline())
+
+	minutely := NewCounter().WithClock(clock).WithBaseline(60, time.Minute).Start()
+	for i := 0; i < 3; i++ {
+		clock.Advance(time.Second)
+		minutely.Increment()
+	}
+
+	testza.AssertInRange(t, minutely.DeviationFromBaseline(), -1e-9, 1e-9)
+}
+
+func TestCounter_EstimatedCompletion(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).Start()
+	testza.AssertTrue(t, c.EstimatedCompletion(100).IsZero())
+
+	for i := 0; i < 25; i++ {
+		clock.Advance(100 * time.Millisecond)
+		c.Increment()
+	}
+
+	// 75 increments left at 10 per second
+	estimate := c.EstimatedCompletion(100)
+	testza.AssertInRange(t, estimate.Sub(clock.Now()).Seconds(), 7.499, 7.501)
+
+	for i := 0; i < 75; i++ {
+		clock.Advance(100 * time.Millisecond)
+		c.Increment()
+	}
+
+	testza.AssertInRange(t, clock.Now().Sub(estimate).Seconds(), -0.001, 0.001)
+	testza.AssertTrue(t, c.EstimatedCompletion(100).IsZero())
+}
+
+func TestCounter_EstimatedCompletion_SlowRate(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).Start()
+
+	clock.Advance(time.Hour)
+	c.Increment()
+
+	// at one increment per hour, the target is far beyond what a time.Duration can hold
+	estimate := c.EstimatedCompletion(math.MaxUint64)
+	testza.AssertTrue(t, estimate.After(clock.Now()))
+	testza.AssertEqual(t, time.Duration(math.MaxInt64), estimate.Sub(clock.Now()))
+}
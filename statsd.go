@@ -0,0 +1,51 @@
+package counter
+
+import (
+	"math"
+	"time"
+)
+
+// StatsDClient is the minimal StatsD client used by WithStatsD.
+// It is satisfied by the DogStatsD client (github.com/DataDog/datadog-go/v5/statsd).
+type StatsDClient interface {
+	Count(name string, value int64, tags []string, rate float64) error
+}
+
+// statsDEmitter holds the increments of a counter that were not yet emitted by WithStatsD.
+type statsDEmitter struct {
+	// pending is the sum of the increments since the last emit. It is guarded by the mutex of the counter.
+	pending uint64
+}
+
+// WithStatsD periodically emits the sum of the increments since the last emit as a StatsD counter metric.
+// Only the delta is sent, so StatsD can aggregate it correctly. The remaining delta is emitted when the counter stops or resets.
+// Only increments are sent: decreases of the count by Decrement, DecrementBy, Add or Set are not, and don't hold back later increments.
+// Counts merged via MergeStats are not sent either.
+// Emitting only happens while the counter is started. Errors returned by the client are ignored.
+// The interval is measured by the clock set via WithClock, if it is a TimerClock. If it is not positive, one second is used.
+func (c *Counter) WithStatsD(client StatsDClient, metricName string, interval time.Duration) *Counter {
+	emitter := &statsDEmitter{}
+	interval = tickInterval(interval)
+
+	emit := func() {
+		c.mutex.Lock()
+		delta := min(emitter.pending, math.MaxInt64)
+		emitter.pending -= delta
+		c.mutex.Unlock()
+
+		if delta > 0 {
+			_ = client.Count(metricName, int64(delta), nil, 1)
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.statsD = append(c.statsD, emitter)
+	c.workers = append(c.workers, func(stop <-chan struct{}) {
+		c.tick(stop, interval, emit)
+		emit()
+	})
+
+	return c
+}
@@ -0,0 +1,105 @@
+package counter
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+type fakeStatsD struct {
+	mutex  sync.Mutex
+	deltas []int64
+}
+
+func (f *fakeStatsD) Count(name string, value int64, _ []string, _ float64) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if name == "events" {
+		f.deltas = append(f.deltas, value)
+	}
+
+	return nil
+}
+
+func (f *fakeStatsD) sum() int64 {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var sum int64
+	for _, d := range f.deltas {
+		sum += d
+	}
+
+	return sum
+}
+
+func TestCounter_WithStatsD(t *testing.T) {
+	client := &fakeStatsD{}
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithStatsD(client, "events", time.Second).Start()
+	clock.waitTimers(1)
+
+	c.IncrementBy(3)
+	clock.Advance(time.Second)
+	testza.AssertEqual(t, []int64{3}, client.deltas)
+
+	// nothing is sent without increments
+	clock.Advance(time.Second)
+	testza.AssertEqual(t, []int64{3}, client.deltas)
+
+	c.Increment()
+	c.Increment()
+	clock.Advance(time.Second)
+	testza.AssertEqual(t, []int64{3, 2}, client.deltas)
+
+	// the remaining delta is sent when the counter stops
+	c.IncrementBy(5)
+	c.Stop()
+	testza.AssertEqual(t, []int64{3, 2, 5}, client.deltas)
+
+	t.Run("Restart", func(t *testing.T) {
+		c.Start()
+		clock.waitTimers(1)
+
+		c.IncrementBy(10)
+		clock.Advance(time.Second)
+		c.Stop()
+
+		testza.AssertEqual(t, []int64{3, 2, 5, 10}, client.deltas)
+	})
+}
+
+func TestCounter_WithStatsD_Decrease(t *testing.T) {
+	client := &fakeStatsD{}
+	c := NewCounter().WithStatsD(client, "events", time.Hour).Start()
+
+	c.IncrementBy(10)
+	c.DecrementBy(2)
+	c.Increment()
+	c.Set(3)
+	c.IncrementBy(4)
+	c.Stop()
+
+	testza.AssertEqual(t, int64(15), client.sum())
+	testza.AssertEqual(t, int64(c.TotalIncremented()), client.sum())
+}
+
+func TestCounter_WithStatsD_InvalidInterval(t *testing.T) {
+	client := &fakeStatsD{}
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithStatsD(client, "events", 0).Start()
+	clock.waitTimers(1)
+
+	// the delta is emitted once per second instead
+	c.Increment()
+	clock.Advance(999 * time.Millisecond)
+	testza.AssertLen(t, client.deltas, 0)
+
+	clock.Advance(time.Millisecond)
+	testza.AssertEqual(t, []int64{1}, client.deltas)
+
+	c.Stop()
+}
@@ -0,0 +1,35 @@
+package counter
+
+import (
+	"maps"
+	"time"
+)
+
+// IncrementTagged increments the counter by 1, like Increment, and attributes the increment to tag.
+// The per-tag counts can be read with Breakdown.
+func (c *Counter) IncrementTagged(tag string) {
+	c.mutex.Lock()
+	if c.tags == nil {
+		c.tags = make(map[string]uint64)
+	}
+
+	after, ok := c.tryIncrement(1, time.Time{})
+	if ok {
+		c.tags[tag]++
+	}
+	c.mutex.Unlock()
+
+	after()
+}
+
+// Breakdown returns the number of increments per tag made via IncrementTagged since the counter was created or reset.
+// Increments made without a tag are only included in the count, not in the breakdown.
+func (c *Counter) Breakdown() map[string]uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	breakdown := make(map[string]uint64, len(c.tags))
+	maps.Copy(breakdown, c.tags)
+
+	return breakdown
+}
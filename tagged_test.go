@@ -0,0 +1,49 @@
+package counter
+
+import (
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestCounter_IncrementTagged(t *testing.T) {
+	c := NewCounter().Start()
+
+	c.IncrementTagged("hit")
+	c.IncrementTagged("hit")
+	c.IncrementTagged("miss")
+	c.Increment()
+
+	testza.AssertEqual(t, uint64(4), c.Count())
+	testza.AssertEqual(t, map[string]uint64{"hit": 2, "miss": 1}, c.Breakdown())
+
+	c.Reset()
+	testza.AssertEqual(t, map[string]uint64{}, c.Breakdown())
+}
+
+func TestCounter_IncrementTagged_CopyFrom(t *testing.T) {
+	src := NewCounter().Start()
+	src.IncrementTagged("hit")
+	src.IncrementTagged("miss")
+
+	c := NewCounter().Start()
+	c.IncrementTagged("stale")
+	c.CopyFrom(src)
+
+	testza.AssertEqual(t, src.Breakdown(), c.Breakdown())
+
+	// both counters are independent afterwards
+	c.IncrementTagged("hit")
+	testza.AssertEqual(t, map[string]uint64{"hit": 1, "miss": 1}, src.Breakdown())
+	testza.AssertEqual(t, map[string]uint64{"hit": 2, "miss": 1}, c.Breakdown())
+
+	t.Run("Gob", func(t *testing.T) {
+		data, err := c.GobEncode()
+		testza.AssertNoError(t, err)
+
+		decoded := NewCounter()
+		decoded.IncrementTagged("stale")
+		testza.AssertNoError(t, decoded.GobDecode(data))
+		testza.AssertEqual(t, c.Breakdown(), decoded.Breakdown())
+	})
+}
@@ -0,0 +1,46 @@
+package counter
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Validate checks that the state of the counter is internally consistent, e.g. after restoring it from a file.
+// It returns an error wrapping ErrCorruptState that describes the first violated invariant, or nil.
+// A counter that is only modified via its methods is always valid.
+func (c *Counter) Validate() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.validate()
+}
+
+// validate checks that the state of the counter is internally consistent.
+// It must be called with c.mutex held.
+func (c *Counter) validate() error {
+	var problem string
+
+	switch {
+	case c.state < StateNeverStarted || c.state > StateStopped:
+		problem = fmt.Sprintf("invalid state %d", c.state)
+	case c.state != StateNeverStarted && c.startedAt.IsZero():
+		problem = "started counter without start time"
+	case c.state == StateStopped && c.stoppedAt.Before(c.startedAt):
+		problem = "stopped before started"
+	case c.maxSamples < 0:
+		problem = fmt.Sprintf("negative max samples %d", c.maxSamples)
+	case c.maxSamples > 0 && len(c.triggers) > c.maxSamples:
+		problem = fmt.Sprintf("%d recorded increments exceed max samples %d", len(c.triggers), c.maxSamples)
+	case !slices.IsSortedFunc(c.samples(), func(a, b time.Time) int { return a.Compare(b) }):
+		problem = "recorded increments are not sorted"
+	case c.seedMinDiff < 0 || c.seedMaxDiff < 0 || c.seedMinDiff > c.seedMaxDiff:
+		problem = "invalid min / max diff"
+	case c.lightHasDiff && c.lightMinDiff > c.lightMaxDiff:
+		problem = "light stats min diff exceeds max diff"
+	default:
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrCorruptState, problem)
+}
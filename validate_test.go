@@ -0,0 +1,37 @@
+package counter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestCounter_Validate(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCounter().WithClock(clock).WithAdvancedStats().Start()
+
+	for i := 0; i < 3; i++ {
+		clock.Advance(time.Second)
+		c.Increment()
+	}
+
+	c.Stop()
+	testza.AssertNoError(t, c.Validate())
+
+	for name, corrupt := range map[string]func(c *Counter){
+		"State":     func(c *Counter) { c.state = 7 },
+		"StartedAt": func(c *Counter) { c.startedAt = time.Time{} },
+		"StoppedAt": func(c *Counter) { c.stoppedAt = c.startedAt.Add(-time.Second) },
+		"Triggers":  func(c *Counter) { c.triggers[0], c.triggers[2] = c.triggers[2], c.triggers[0] },
+		"MinMax":    func(c *Counter) { c.seedMinDiff, c.seedMaxDiff = time.Second, time.Millisecond },
+	} {
+		t.Run(name, func(t *testing.T) {
+			var broken Counter
+			broken.CopyFrom(c)
+			corrupt(&broken)
+
+			testza.AssertErrorIs(t, broken.Validate(), ErrCorruptState)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package counter
+
+import (
+	"context"
+	"math"
+)
+
+// Add adds delta, which may be negative, to the counter.
+// Together with Done and WaitZero, it allows to use the counter like a sync.WaitGroup with an observable count and rate.
+// Unlike a sync.WaitGroup, the count saturates at 0 instead of panicking.
+func (c *Counter) Add(delta int) {
+	if delta >= 0 {
+		c.IncrementBy(uint64(delta))
+		return
+	}
+
+	if delta == math.MinInt {
+		c.DecrementBy(uint64(math.MaxInt) + 1)
+		return
+	}
+
+	c.DecrementBy(uint64(-delta))
+}
+
+// Done decrements the counter by 1.
+func (c *Counter) Done() {
+	c.Decrement()
+}
+
+// WaitZero blocks until the count is 0, or ctx is done.
+// It returns the error of ctx, if ctx is done before the count reaches 0.
+func (c *Counter) WaitZero(ctx context.Context) error {
+	c.mutex.Lock()
+
+	if c.count == 0 {
+		c.mutex.Unlock()
+		return nil
+	}
+
+	ch := make(chan struct{})
+	c.zeroWaiters = append(c.zeroWaiters, ch)
+	c.mutex.Unlock()
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		for i, waiter := range c.zeroWaiters {
+			if waiter == ch {
+				c.zeroWaiters = append(c.zeroWaiters[:i], c.zeroWaiters[i+1:]...)
+				break
+			}
+		}
+
+		return ctx.Err()
+	}
+}
+
+// notifyZero releases all callers of WaitZero, if the count is 0.
+// It must be called with c.mutex held, whenever the count might have dropped to 0.
+func (c *Counter) notifyZero() {
+	if c.count != 0 || len(c.zeroWaiters) == 0 {
+		return
+	}
+
+	for _, waiter := range c.zeroWaiters {
+		close(waiter)
+	}
+
+	c.zeroWaiters = nil
+}
@@ -0,0 +1,56 @@
+package counter_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MarvinJWendt/testza"
+
+	"atomicgo.dev/counter"
+)
+
+func TestCounter_WaitZero(t *testing.T) {
+	t.Run("Done", func(t *testing.T) {
+		c := counter.NewCounter().Start()
+		c.Add(50)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				time.Sleep(time.Millisecond)
+				c.Done()
+			}()
+		}
+
+		testza.AssertCompletesIn(t, time.Second, func() {
+			testza.AssertNoError(t, c.WaitZero(context.Background()))
+		})
+		testza.AssertEqual(t, uint64(0), c.Count())
+		wg.Wait()
+	})
+
+	t.Run("Negative Add", func(t *testing.T) {
+		c := counter.NewCounter()
+		c.Add(3)
+		c.Add(-5)
+
+		testza.AssertEqual(t, uint64(0), c.Count())
+		testza.AssertNoError(t, c.WaitZero(context.Background()))
+	})
+
+	t.Run("Context canceled", func(t *testing.T) {
+		c := counter.NewCounter()
+		c.Add(1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		testza.AssertErrorIs(t, c.WaitZero(ctx), context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,19 @@
+package counter
+
+import "math"
+
+// AddWeighted adds weight, which may be fractional, to the weighted count of the counter, e.g. to count "weighted requests".
+// The weighted count is kept separately from the count, and is updated without locking the counter.
+func (c *Counter) AddWeighted(weight float64) {
+	for {
+		old := c.weighted.Load()
+		if c.weighted.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+weight)) {
+			return
+		}
+	}
+}
+
+// WeightedCount returns the sum of all weights added via AddWeighted since the counter was created or reset.
+func (c *Counter) WeightedCount() float64 {
+	return math.Float64frombits(c.weighted.Load())
+}
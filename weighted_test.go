@@ -0,0 +1,37 @@
+package counter
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/MarvinJWendt/testza"
+)
+
+func TestCounter_AddWeighted(t *testing.T) {
+	c := NewCounter()
+	weights := []float64{0.5, 1.25, 2, 0.1}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for _, w := range weights {
+				c.AddWeighted(w)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	testza.AssertInRange(t, c.WeightedCount(), 385-1e-9, 385+1e-9)
+	testza.AssertEqual(t, uint64(0), c.Count())
+
+	c.AddWeighted(-5)
+	testza.AssertInRange(t, c.WeightedCount(), 380-1e-9, 380+1e-9)
+
+	c.Reset()
+	testza.AssertEqual(t, 0.0, c.WeightedCount())
+}